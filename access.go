@@ -0,0 +1,219 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// PathError is returned by the path accessors (Lookup, String, Int64, and so on) when the value
+// at a path is missing, or is not the kind of value that was needed.
+type PathError struct {
+	// Path is the JSON Pointer (RFC 6901) of the value that was wrong, from the root of the document.
+	// When a lookup fails part of the way along a path, this is as far as it got.
+	Path string
+
+	// Expected is the kind of value that was needed. It is KindMissing if any kind would have done.
+	Expected Kind
+
+	// Actual is the kind of value that was found. It is KindMissing if there was nothing there.
+	Actual Kind
+
+	// Err is set when the value was the right kind, but could not be converted (such as a number
+	// that doesn't fit in an int64).
+	Err error
+}
+
+func (e *PathError) Error() string {
+	switch {
+	case e.Err != nil:
+		return fmt.Sprintf("jsonnode: %q: %v", e.Path, e.Err)
+
+	case e.Actual == KindMissing:
+		return fmt.Sprintf("jsonnode: %q: not found", e.Path)
+	}
+
+	return fmt.Sprintf("jsonnode: %q: expected %v, found %v", e.Path, e.Expected, e.Actual)
+}
+
+// Unwrap returns Err, or ErrNotFound if nothing was found at the path.
+func (e *PathError) Unwrap() error {
+	if e.Err == nil && e.Actual == KindMissing {
+		return ErrNotFound
+	}
+
+	return e.Err
+}
+
+// Lookup follows path from this node, returning the node found there.
+// Each element of path is either a string (the name of an object member) or an int (an array index).
+// If the path can't be followed, a *PathError is returned saying where and why.
+func (jn *JSONNode) Lookup(path ...interface{}) (*JSONNode, error) {
+	return jn.lookupPath(KindMissing, path)
+}
+
+// lookupPath implements Lookup. expected is the kind of value the caller needs at the end of path,
+// and is only used for the error if nothing is there.
+func (jn *JSONNode) lookupPath(expected Kind, path []interface{}) (*JSONNode, error) {
+	node := jn
+
+	if !node.Exists() {
+		return nil, &PathError{Path: node.JSONPointer(), Expected: expected, Actual: KindMissing}
+	}
+
+	for _, segment := range path {
+		var next *JSONNode
+		var token string
+
+		switch segment := segment.(type) {
+		case string:
+			if !node.IsObject() {
+				return nil, &PathError{Path: node.JSONPointer(), Expected: KindObject, Actual: node.Kind()}
+			}
+
+			next = node.Get(segment)
+			token = escapePointerToken(segment)
+
+		case int:
+			if !node.IsArray() {
+				return nil, &PathError{Path: node.JSONPointer(), Expected: KindArray, Actual: node.Kind()}
+			}
+
+			next = node.Index(segment)
+			token = strconv.Itoa(segment)
+
+		default:
+			return nil, fmt.Errorf("jsonnode: path elements must be a string or int, not %T", segment)
+		}
+
+		if next == nil {
+			return nil, &PathError{Path: node.JSONPointer() + "/" + token, Expected: expected, Actual: KindMissing}
+		}
+
+		node = next
+	}
+
+	return node, nil
+}
+
+// lookupKind is like lookupPath, but also checks the kind of the node found.
+func (jn *JSONNode) lookupKind(expected Kind, path []interface{}) (*JSONNode, error) {
+	node, err := jn.lookupPath(expected, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if kind := node.Kind(); kind != expected {
+		return nil, &PathError{Path: node.JSONPointer(), Expected: expected, Actual: kind}
+	}
+
+	return node, nil
+}
+
+// String gets the string at path (see Lookup).
+func (jn *JSONNode) String(path ...interface{}) (string, error) {
+	node, err := jn.lookupKind(KindString, path)
+	if err != nil {
+		return "", err
+	}
+
+	val, _ := node.ValueAsString()
+
+	return val, nil
+}
+
+// Bool gets the bool at path (see Lookup).
+func (jn *JSONNode) Bool(path ...interface{}) (bool, error) {
+	node, err := jn.lookupKind(KindBool, path)
+	if err != nil {
+		return false, err
+	}
+
+	val, _ := node.ValueAsBool()
+
+	return val, nil
+}
+
+// Float64 gets the number at path (see Lookup) as a float64.
+func (jn *JSONNode) Float64(path ...interface{}) (float64, error) {
+	node, err := jn.lookupKind(KindNumber, path)
+	if err != nil {
+		return 0, err
+	}
+
+	val, ok := node.ValueAsFloat64()
+	if !ok {
+		return 0, &PathError{Path: node.JSONPointer(), Expected: KindNumber, Actual: KindNumber, Err: ErrOverflow}
+	}
+
+	return val, nil
+}
+
+// Int64 gets the number at path (see Lookup) as an int64.
+// The *PathError wraps ErrPrecisionLoss or ErrOverflow if the number isn't an integer or doesn't fit.
+func (jn *JSONNode) Int64(path ...interface{}) (int64, error) {
+	node, err := jn.lookupKind(KindNumber, path)
+	if err != nil {
+		return 0, err
+	}
+
+	val, err := node.ValueAsInt64()
+	if err != nil {
+		return 0, node.numberError(err)
+	}
+
+	return val, nil
+}
+
+// Uint64 gets the number at path (see Lookup) as a uint64.
+// The *PathError wraps ErrPrecisionLoss or ErrOverflow if the number isn't an integer or doesn't fit.
+func (jn *JSONNode) Uint64(path ...interface{}) (uint64, error) {
+	node, err := jn.lookupKind(KindNumber, path)
+	if err != nil {
+		return 0, err
+	}
+
+	val, err := node.ValueAsUint64()
+	if err != nil {
+		return 0, node.numberError(err)
+	}
+
+	return val, nil
+}
+
+// Number gets the number at path (see Lookup) as a json.Number.
+func (jn *JSONNode) Number(path ...interface{}) (json.Number, error) {
+	node, err := jn.lookupKind(KindNumber, path)
+	if err != nil {
+		return "", err
+	}
+
+	val, err := node.ValueAsNumber()
+	if err != nil {
+		return "", node.numberError(err)
+	}
+
+	return val, nil
+}
+
+// Array gets the elements of the array at path (see Lookup).
+func (jn *JSONNode) Array(path ...interface{}) ([]*JSONNode, error) {
+	node, err := jn.lookupKind(KindArray, path)
+	if err != nil {
+		return nil, err
+	}
+
+	val, _ := node.ValueAsSlice()
+
+	return val, nil
+}
+
+// numberError wraps an error from converting this node's number in a *PathError.
+func (jn *JSONNode) numberError(err error) error {
+	if errors.Is(err, ErrNotNumber) {
+		return &PathError{Path: jn.JSONPointer(), Expected: KindNumber, Actual: jn.Kind()}
+	}
+
+	return &PathError{Path: jn.JSONPointer(), Expected: KindNumber, Actual: KindNumber, Err: err}
+}
@@ -0,0 +1,181 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONNodePathAccessors(t *testing.T) {
+	t.Parallel()
+
+	jn, err := Unmarshal([]byte(platterJSON), DecoderOptions{UseNumber: true})
+	require.NoError(t, err)
+
+	t.Run("found", func(t *testing.T) {
+		t.Parallel()
+
+		s, err := jn.String("with", "meat")
+		require.NoError(t, err)
+		require.Equal(t, "prosciutto", s)
+
+		s, err = jn.String("cheeses", 1)
+		require.NoError(t, err)
+		require.Equal(t, "swiss", s)
+
+		count, err := jn.Int64("with", "fruit", 0, "count")
+		require.NoError(t, err)
+		require.Equal(t, int64(8), count)
+
+		ucount, err := jn.Uint64("with", "fruit", 1, "count")
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), ucount)
+
+		f, err := jn.Float64("with", "fruit", 1, "count")
+		require.NoError(t, err)
+		require.Equal(t, float64(3), f)
+
+		n, err := jn.Number("with", "fruit", 1, "count")
+		require.NoError(t, err)
+		require.Equal(t, json.Number("3"), n)
+
+		elems, err := jn.Array("cheeses")
+		require.NoError(t, err)
+		require.Len(t, elems, 3)
+
+		node, err := jn.Lookup("with")
+		require.NoError(t, err)
+		require.Equal(t, "/with", node.JSONPointer())
+
+		node, err = jn.Lookup()
+		require.NoError(t, err)
+		require.Equal(t, jn, node)
+
+		// Relative to a child, the path in errors is still from the root
+		_, err = jn.Get("with").Bool("meat")
+		require.EqualError(t, err, `jsonnode: "/with/meat": expected bool, found string`)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name     string
+			get      func() error
+			expected PathError
+		}{
+			{
+				name: "missing",
+				get: func() error {
+					_, err := jn.String("with", "bread")
+					return err
+				},
+				expected: PathError{Path: "/with/bread", Expected: KindString, Actual: KindMissing},
+			},
+			{
+				name: "missing index",
+				get: func() error {
+					_, err := jn.String("cheeses", 3)
+					return err
+				},
+				expected: PathError{Path: "/cheeses/3", Expected: KindString, Actual: KindMissing},
+			},
+			{
+				name: "wrong kind",
+				get: func() error {
+					_, err := jn.String("with", "fruit", 0, "count")
+					return err
+				},
+				expected: PathError{Path: "/with/fruit/0/count", Expected: KindString, Actual: KindNumber},
+			},
+			{
+				name: "not an object along the way",
+				get: func() error {
+					_, err := jn.String("platter", "material")
+					return err
+				},
+				expected: PathError{Path: "/platter", Expected: KindObject, Actual: KindString},
+			},
+			{
+				name: "not an array along the way",
+				get: func() error {
+					_, err := jn.String("with", 0)
+					return err
+				},
+				expected: PathError{Path: "/with", Expected: KindArray, Actual: KindObject},
+			},
+			{
+				name: "lookup missing",
+				get: func() error {
+					_, err := jn.Lookup("nope")
+					return err
+				},
+				expected: PathError{Path: "/nope", Expected: KindMissing, Actual: KindMissing},
+			},
+			{
+				name: "nil node",
+				get: func() error {
+					_, err := jn.Get("nope").Bool()
+					return err
+				},
+				expected: PathError{Path: "", Expected: KindBool, Actual: KindMissing},
+			},
+		}
+
+		for _, test := range tests {
+			err := test.get()
+			require.Error(t, err, test.name)
+
+			var pathErr *PathError
+			require.True(t, errors.As(err, &pathErr), test.name)
+			require.Equal(t, test.expected, *pathErr, test.name)
+
+			require.Equal(t, test.expected.Actual == KindMissing, errors.Is(err, ErrNotFound), test.name)
+		}
+	})
+
+	t.Run("conversion errors", func(t *testing.T) {
+		t.Parallel()
+
+		doc := NewFromValue(map[string]interface{}{"half": 0.5, "huge": json.Number("1e400")})
+
+		_, err := doc.Int64("half")
+		require.EqualError(t, err, `jsonnode: "/half": jsonnode: number would lose precision: 0.5 is not an integer`)
+
+		var pathErr *PathError
+		require.True(t, errors.As(err, &pathErr))
+		require.Equal(t, KindNumber, pathErr.Actual)
+		require.True(t, errors.Is(err, ErrPrecisionLoss))
+
+		_, err = doc.Float64("huge")
+		require.True(t, errors.Is(err, ErrOverflow), "%v", err)
+
+		_, err = doc.Lookup(true)
+		require.EqualError(t, err, "jsonnode: path elements must be a string or int, not bool")
+	})
+}
+
+func ExampleJSONNode_String() {
+	jn := new(JSONNode)
+	err := json.Unmarshal([]byte(`{"with": {"fruit": [{"type": "grapes"}, {"type": 3}]}}`), jn)
+	if err != nil {
+		panic(err)
+	}
+
+	fruit, err := jn.String("with", "fruit", 0, "type")
+	fmt.Println(fruit, err)
+
+	_, err = jn.String("with", "fruit", 1, "type")
+	fmt.Println(err)
+
+	_, err = jn.String("with", "meat")
+	fmt.Println(err)
+
+	// Output:
+	// grapes <nil>
+	// jsonnode: "/with/fruit/1/type": expected string, found number
+	// jsonnode: "/with/meat": not found
+}
@@ -0,0 +1,108 @@
+package jsonnode
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// sharedGeneration is incremented when something that might be in more than one document is
+// changed, which makes the cached parent values of every node out of date.
+var sharedGeneration uint64
+
+// sharedDocument is the document of an *Object that has been seen in more than one document (or
+// in a document without one). Changing it makes every cached parent value out of date.
+var sharedDocument = new(document)
+
+// generation is when a cached value was looked up. It is out of date once either of the counters
+// it was read from has changed.
+type generation struct {
+	doc    uint64
+	shared uint64
+}
+
+// generation gets the current generation of the document, for checking whether a cached value is
+// out of date.
+func (d *document) generation() generation {
+	gen := generation{shared: atomic.LoadUint64(&sharedGeneration)}
+	if d != nil {
+		gen.doc = atomic.LoadUint64(&d.gen)
+	}
+
+	return gen
+}
+
+// invalidate makes the cached parent values of the nodes of the document out of date. It must be
+// called whenever a value that a node of the document could be looking at is changed.
+func (d *document) invalidate() {
+	if d == nil || d == sharedDocument {
+		atomic.AddUint64(&sharedGeneration, 1)
+		return
+	}
+
+	atomic.AddUint64(&d.gen, 1)
+}
+
+// adopt records that the object has been seen by a node of doc, so that changing the object
+// invalidates the nodes of doc. An object that no node has seen can be changed without
+// invalidating anything, because no node can have cached anything under it.
+func (o *Object) adopt(doc *document) {
+	if doc == nil {
+		doc = sharedDocument
+	}
+
+	ptr := (*unsafe.Pointer)(unsafe.Pointer(&o.doc))
+
+	for {
+		current := (*document)(atomic.LoadPointer(ptr))
+		if current == doc || current == sharedDocument {
+			return
+		}
+
+		next := doc
+		if current != nil {
+			// In more than one document
+			next = sharedDocument
+		}
+
+		if atomic.CompareAndSwapPointer(ptr, unsafe.Pointer(current), unsafe.Pointer(next)) {
+			return
+		}
+	}
+}
+
+// invalidate makes the cached parent values of the nodes that have seen the object out of date.
+func (o *Object) invalidate() {
+	doc := (*document)(atomic.LoadPointer((*unsafe.Pointer)(unsafe.Pointer(&o.doc))))
+	if doc != nil {
+		doc.invalidate()
+	}
+}
+
+// parentCache is the value of a node's parent, as of a generation.
+// It can be shared by all of the children of the same parent.
+type parentCache struct {
+	value      interface{}
+	generation generation
+}
+
+// parentValue gets the value of the parent of this node. The value is cached, so the parent (and
+// its parent, and so on) is only looked up again once something in the document has been changed.
+func (jn *JSONNode) parentValue() interface{} {
+	gen := jn.doc.generation()
+
+	// Nodes can be read from more than one goroutine at a time (once a lazily decoded document has
+	// been fully decoded), so the cache is updated atomically
+	ptr := (*unsafe.Pointer)(unsafe.Pointer(&jn.parentCache))
+
+	if cache := (*parentCache)(atomic.LoadPointer(ptr)); cache != nil && cache.generation == gen {
+		return cache.value
+	}
+
+	value, _ := jn.parent.lookupShallow()
+
+	// If looking up the value changed anything, this is already out of date and will be looked up
+	// again next time.
+	atomic.StorePointer(ptr, unsafe.Pointer(&parentCache{value: value, generation: gen}))
+
+	return value
+}
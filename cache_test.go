@@ -0,0 +1,163 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONNodeParentCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("changes are seen", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+		count := jn.Pointer("/with/fruit/1/count")
+		meat := jn.Pointer("/with/meat")
+		require.Equal(t, 3.0, count.Value())
+
+		// Replacing an ancestor
+		require.NoError(t, jn.SetPointer("/with/fruit/1", map[string]interface{}{"count": 4}))
+		require.Equal(t, 4.0, count.Value())
+
+		// Through the *Object held by an ancestor
+		jn.Get("with").OrderedValue().(*Object).Set("fruit", []interface{}{})
+		require.False(t, count.Exists())
+
+		require.NoError(t, jn.Get("with").Set("fruit", []interface{}{nil, map[string]interface{}{"count": 5}}))
+		require.Equal(t, 5.0, count.Value())
+
+		// Elements shifting
+		require.NoError(t, jn.Pointer("/with/fruit").RemoveAt(0))
+		require.False(t, count.Exists())
+
+		require.NoError(t, jn.Pointer("/with/fruit").InsertAt(0, "first"))
+		require.Equal(t, 5.0, count.Value())
+
+		// Replacing the whole document
+		require.NoError(t, json.Unmarshal([]byte(`{"with": {"meat": "salami"}}`), jn))
+		require.False(t, count.Exists())
+		require.Equal(t, "salami", meat.Value())
+
+		require.NoError(t, jn.Decode(jn))
+		require.Equal(t, "salami", meat.Value())
+
+		require.NoError(t, jn.SetPointer("", map[string]interface{}{"with": map[string]interface{}{"meat": "ham"}}))
+		require.Equal(t, "ham", meat.Value())
+	})
+
+	t.Run("elements", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+		cheeses, ok := jn.Get("cheeses").ValueAsSlice()
+		require.True(t, ok)
+
+		require.NoError(t, cheeses[1].SetPointer("", "gouda"))
+		require.Equal(t, []interface{}{"cheddar", "gouda", "manchego"}, jn.Get("cheeses").Value())
+		require.Equal(t, "gouda", cheeses[1].Value())
+
+		require.NoError(t, jn.Set("cheeses", []interface{}{"brie"}))
+		require.Equal(t, "brie", cheeses[0].Value())
+		require.False(t, cheeses[1].Exists())
+	})
+
+	t.Run("documents are separate", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+		count := jn.Pointer("/with/fruit/1/count")
+		require.Equal(t, 3.0, count.Value())
+
+		gen := jn.doc.generation().doc
+		cache := count.parentCache
+
+		other := unmarshalPlatter(t)
+		require.NoError(t, other.Set("platter", "wood"))
+		require.NoError(t, other.Pointer("/with/fruit").Append(1))
+
+		// Building new values doesn't change anything that a node could be looking at
+		_, err := json.Marshal(Patch{{Op: OpAdd, Path: "/a", Value: 1}})
+		require.NoError(t, err)
+
+		_, err = CreateMergePatch(jn, other)
+		require.NoError(t, err)
+
+		_, err = NewImmutable(jn).With(Path{"with", "meat"}, "ham")
+		require.NoError(t, err)
+
+		require.Equal(t, 3.0, count.Value())
+		require.Equal(t, gen, jn.doc.generation().doc)
+		require.True(t, cache == count.parentCache)
+	})
+
+	t.Run("object in more than one document", func(t *testing.T) {
+		t.Parallel()
+
+		inner := NewObject()
+		inner.Set("b", 1.0)
+
+		shared := NewObject()
+		shared.Set("a", inner)
+
+		first, second := new(JSONNode), new(JSONNode)
+		first.init()
+		first.data = shared
+		second.init()
+		second.data = shared
+
+		firstB, secondB := first.Pointer("/a/b"), second.Pointer("/a/b")
+		require.Equal(t, 1.0, firstB.Value())
+		require.Equal(t, 1.0, secondB.Value())
+
+		// Changing it through one of the documents
+		require.NoError(t, first.Set("a", map[string]interface{}{"b": 2.0}))
+		require.Equal(t, 2.0, firstB.Value())
+		require.Equal(t, 2.0, secondB.Value())
+
+		shared.Delete("a")
+		require.False(t, firstB.Exists())
+		require.False(t, secondB.Exists())
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		im := NewImmutable(unmarshalPlatter(t))
+		count := im.Pointer("/with/fruit/0/count")
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+
+			go func() {
+				defer wg.Done()
+
+				for j := 0; j < 100; j++ {
+					if v := count.Value(); v != 8.0 {
+						t.Errorf("expected 8, got %v", v)
+						return
+					}
+				}
+			}()
+
+			go func() {
+				defer wg.Done()
+
+				// Changing another document doesn't affect this one
+				other := unmarshalPlatter(t)
+				for j := 0; j < 100; j++ {
+					if err := other.Set("platter", j); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}()
+		}
+
+		wg.Wait()
+	})
+}
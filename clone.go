@@ -0,0 +1,48 @@
+package jsonnode
+
+// Clone makes a deep copy of this node and everything under it, as the root of a new document.
+// Changes to the copy don't affect the original, and vice versa.
+// nil is returned if this node does not exist.
+func (jn *JSONNode) Clone() *JSONNode {
+	value, ok := jn.lookup()
+	if !ok {
+		return nil
+	}
+
+	return NewFromValue(value)
+}
+
+// Detach removes this node from its parent, and makes it the root of a new document holding its
+// value. Nodes previously gotten from this one (with Get, Index, etc.) still refer to it.
+// Other nodes that referred to the same member or element of the parent no longer do.
+// Detaching a root node does nothing.
+func (jn *JSONNode) Detach() error {
+	if jn == nil {
+		return ErrNilNode
+	}
+
+	value, ok := jn.lookup()
+	if !ok {
+		return ErrNotFound
+	}
+
+	if jn.parent == nil {
+		return nil
+	}
+
+	var err error
+	if jn.index >= 0 {
+		err = jn.parent.RemoveAt(jn.index)
+	} else {
+		err = jn.parent.Delete(jn.fieldName)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	jn.init()
+	jn.data = value
+
+	return nil
+}
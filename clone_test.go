@@ -0,0 +1,126 @@
+package jsonnode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONNodeClone(t *testing.T) {
+	t.Parallel()
+
+	t.Run("independent", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+		with := jn.Get("with").Clone()
+		require.Equal(t, "", with.JSONPointer())
+		require.True(t, Equal(jn.Get("with"), with))
+
+		require.NoError(t, with.SetPointer("/fruit/0/count", 100))
+		require.NoError(t, with.Delete("meat"))
+
+		require.Equal(t, 8.0, jn.Pointer("/with/fruit/0/count").Value())
+		require.Equal(t, "prosciutto", jn.Pointer("/with/meat").Value())
+
+		require.NoError(t, jn.Get("with").Get("fruit").Append("figs"))
+		require.Equal(t, 2, with.Get("fruit").Len())
+	})
+
+	t.Run("stamp", func(t *testing.T) {
+		t.Parallel()
+
+		template := unmarshalPlatter(t).Get("with")
+
+		outputs := make([]*JSONNode, 3)
+		for i := range outputs {
+			outputs[i] = New()
+			require.NoError(t, outputs[i].Set("with", template.Clone()))
+			require.NoError(t, outputs[i].SetPointer("/with/id", i))
+		}
+
+		for i, output := range outputs {
+			require.Equal(t, float64(i), output.Pointer("/with/id").Value())
+		}
+
+		require.Nil(t, template.Get("id"))
+	})
+
+	t.Run("scalar", func(t *testing.T) {
+		t.Parallel()
+
+		clone := unmarshalPlatter(t).Pointer("/cheeses/1").Clone()
+		require.Equal(t, "swiss", clone.Value())
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+
+		var jn *JSONNode
+		require.Nil(t, jn.Clone())
+
+		jn = unmarshalPlatter(t)
+		fruit := jn.Pointer("/with/fruit")
+		require.NoError(t, jn.Delete("with"))
+		require.Nil(t, fruit.Clone())
+	})
+}
+
+func TestJSONNodeDetach(t *testing.T) {
+	t.Parallel()
+
+	t.Run("member", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+		with := jn.Get("with")
+		fruit := with.Get("fruit")
+
+		require.NoError(t, with.Detach())
+		require.Nil(t, jn.Get("with"))
+		require.Equal(t, "", with.JSONPointer())
+		require.Equal(t, "/fruit", fruit.JSONPointer())
+		require.Equal(t, 2, fruit.Len())
+
+		other := New()
+		require.NoError(t, other.Set("sides", with))
+		require.Equal(t, "prosciutto", other.Pointer("/sides/meat").Value())
+	})
+
+	t.Run("element", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+		cheese := jn.Pointer("/cheeses/1")
+
+		require.NoError(t, cheese.Detach())
+		require.Equal(t, "swiss", cheese.Value())
+		require.Equal(t, []interface{}{"cheddar", "manchego"}, jn.Get("cheeses").Value())
+
+		require.NoError(t, cheese.SetPointer("", "gouda"))
+		require.Equal(t, []interface{}{"cheddar", "manchego"}, jn.Get("cheeses").Value())
+	})
+
+	t.Run("root", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+		require.NoError(t, jn.Detach())
+		require.Equal(t, "slate", jn.Get("platter").Value())
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+
+		var jn *JSONNode
+		require.Equal(t, ErrNilNode, jn.Detach())
+
+		jn = unmarshalPlatter(t)
+		meat := jn.Pointer("/with/meat")
+		require.NoError(t, jn.Get("with").Delete("meat"))
+
+		err := meat.Detach()
+		require.True(t, errors.Is(err, ErrNotFound), "%v", err)
+	})
+}
@@ -0,0 +1,107 @@
+package jsonnode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// DecoderOptions controls how JSON is decoded by Unmarshal, and how values are decoded by
+// DecodeWithOptions.
+// The zero value decodes the same way as UnmarshalJSON.
+type DecoderOptions struct {
+	// UseNumber decodes JSON numbers as json.Number rather than float64.
+	// This keeps the original number literal, so large integers (such as 64-bit IDs) don't lose
+	// precision, and numbers are marshalled back to JSON exactly as they were (1.10 stays 1.10).
+	UseNumber bool
+
+	// DisallowUnknownFields makes DecodeWithOptions return an error when a JSON object has a member
+	// that doesn't match any field of the struct it is decoded into. Unmarshal ignores it.
+	DisallowUnknownFields bool
+
+	// Lazy makes Unmarshal check that the JSON is valid, but only decode values when they are first
+	// used, such as with Get or Index, or everything under a node with Value. Anything not decoded,
+	// and any string, number, bool, or null that has only been read, is marshalled back to JSON as
+	// it was.
+	// The data passed to Unmarshal is kept, so it must not be changed afterwards.
+	// Decoding a value changes the document, even when it is only being read, so a lazily decoded
+	// document must not be used from more than one goroutine at a time until everything in it has
+	// been decoded, such as by calling Value on the root.
+	// A number too large for a float64 is kept as a json.Number rather than being an error.
+	// DecodeWithOptions ignores it.
+	Lazy bool
+
+	// Syntax is the flavor of JSON that Unmarshal accepts, such as JSON with comments (SyntaxJSONC)
+	// for configuration files that are edited by hand. It is converted to standard JSON first (see
+	// Standardize), so a document decoded from it is always marshalled as standard JSON.
+	// The zero value is standard JSON. Decoder and DecodeWithOptions ignore it.
+	// A syntax error in JSONC or JSON5 is a *SyntaxError, giving its line and column.
+	Syntax Syntax
+
+	// RejectNonFinite makes JSON5's Infinity, -Infinity, and NaN an error, rather than them being
+	// decoded as null (see StandardizeOptions).
+	RejectNonFinite bool
+}
+
+// Unmarshal decodes JSON into a new *JSONNode, as controlled by opts.
+func Unmarshal(data []byte, opts DecoderOptions) (*JSONNode, error) {
+	var s *standardizer
+
+	if opts.Syntax != SyntaxJSON {
+		var err error
+
+		s, err = standardize(data, opts.Syntax, StandardizeOptions{RejectNonFinite: opts.RejectNonFinite})
+		if err != nil {
+			return nil, err
+		}
+
+		data = s.out
+	}
+
+	if opts.Lazy && json.Valid(data) {
+		jn := new(JSONNode)
+		jn.init()
+		jn.data = &rawValue{data: bytes.TrimSpace(data), useNumber: opts.UseNumber}
+		jn.doc = &document{lazy: true}
+
+		return jn, nil
+	}
+
+	// Invalid JSON is decoded normally, to get the error
+	value, err := decode(data, opts)
+	if err != nil {
+		if s != nil {
+			err = s.translateError(err)
+		}
+
+		return nil, err
+	}
+
+	jn := new(JSONNode)
+	jn.init()
+	jn.data = value
+
+	return jn, nil
+}
+
+// decode decodes a single JSON value, keeping JSON object members in order.
+func decode(data []byte, opts DecoderOptions) (interface{}, error) {
+	d := NewDecoderWithOptions(bytes.NewReader(data), opts)
+
+	value, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure there's nothing else after the value
+	if _, err = d.Token(); err != io.EOF {
+		if err == nil {
+			err = errors.New("jsonnode: invalid data after top-level value")
+		}
+
+		return nil, err
+	}
+
+	return value, nil
+}
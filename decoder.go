@@ -0,0 +1,324 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Decoder reads JSON from a stream, a token or a value at a time, so that large documents can be
+// processed without holding all of them in memory.
+// Seek skips ahead to part of a document, and Next decodes the value there into a *JSONNode.
+//
+// For example, this decodes each element of the "items" array, one at a time:
+//
+//	dec := jsonnode.NewDecoder(r)
+//	err := dec.Seek("/items/-")
+//	...
+//	for {
+//		item, err := dec.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+type Decoder struct {
+	dec  *json.Decoder
+	opts DecoderOptions
+
+	// stack has an entry for each JSON object or array the next token is in
+	stack []decoderFrame
+}
+
+// decoderFrame is where a Decoder is in a JSON object or array.
+type decoderFrame struct {
+	object bool
+
+	// key is set if the next token is the name of a member of the object
+	key bool
+}
+
+// NewDecoder creates a Decoder that reads from r. It may read more from r than it needs.
+func NewDecoder(r io.Reader) *Decoder {
+	return NewDecoderWithOptions(r, DecoderOptions{})
+}
+
+// NewDecoderWithOptions is like NewDecoder, with values decoded as controlled by opts.
+// opts.DisallowUnknownFields is ignored.
+func NewDecoderWithOptions(r io.Reader, opts DecoderOptions) *Decoder {
+	dec := json.NewDecoder(r)
+	if opts.UseNumber {
+		dec.UseNumber()
+	}
+
+	return &Decoder{dec: dec, opts: opts}
+}
+
+// Token gets the next JSON token, the same as json.Decoder.Token does. io.EOF is returned at the end
+// of the input.
+func (d *Decoder) Token() (json.Token, error) {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	if n := len(d.stack); n > 0 && d.stack[n-1].key && tok != json.Delim('}') {
+		// The name of an object member
+		d.stack[n-1].key = false
+
+		return tok, nil
+	}
+
+	switch tok {
+	case json.Delim('{'), json.Delim('['):
+		d.stack = append(d.stack, decoderFrame{object: tok == json.Delim('{'), key: tok == json.Delim('{')})
+
+	case json.Delim('}'), json.Delim(']'):
+		d.stack = d.stack[:len(d.stack)-1]
+		d.valueDone()
+
+	default:
+		d.valueDone()
+	}
+
+	return tok, nil
+}
+
+// valueDone updates where the Decoder is after a whole value has been read.
+func (d *Decoder) valueDone() {
+	if n := len(d.stack); n > 0 && d.stack[n-1].object {
+		d.stack[n-1].key = true
+	}
+}
+
+// More reports whether there is another element in the current JSON array or object, or another
+// value in the input if the Decoder is not in an array or object.
+func (d *Decoder) More() bool {
+	return d.dec.More()
+}
+
+// Next decodes the next value into a new *JSONNode. If the end of the JSON array or object the
+// Decoder is in has been reached, it is read past and io.EOF is returned. io.EOF is also returned
+// at the end of the input.
+// In a JSON object, the name of the member has to be read with Token first.
+// If the Decoder was created with DecoderOptions.Lazy, the value is decoded lazily.
+func (d *Decoder) Next() (*JSONNode, error) {
+	if !d.dec.More() {
+		// Read the closing ']' or '}', or the end of the input, checking for errors
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+
+		return nil, io.EOF
+	}
+
+	if n := len(d.stack); n > 0 && d.stack[n-1].key {
+		return nil, errors.New("jsonnode: Next called before the name of an object member was read")
+	}
+
+	if d.opts.Lazy {
+		var raw json.RawMessage
+		if err := d.dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+
+		d.valueDone()
+
+		return Unmarshal(raw, d.opts)
+	}
+
+	value, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+
+	jn := new(JSONNode)
+	jn.init()
+	jn.data = value
+
+	return jn, nil
+}
+
+// Seek reads ahead to the value at the JSON pointer (RFC 6901) ptr, relative to the next value, so
+// that it is the next value to be read. If the last reference token of ptr is "-", the next value
+// must be a JSON array and Seek stops before its first element, so Next gets each element in turn.
+// Everything that is skipped over is not decoded. Seek can only move forward, and if an error is
+// returned, where the Decoder is in the input is not defined.
+func (d *Decoder) Seek(ptr string) error {
+	tokens, err := parsePointer(ptr)
+	if err != nil {
+		return err
+	}
+
+	for i, token := range tokens {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tok {
+		case json.Delim('{'):
+			found, err := d.seekMember(token)
+			if err != nil {
+				return err
+			}
+
+			if !found {
+				return fmt.Errorf("%w: %q", ErrNotFound, ptr)
+			}
+
+		case json.Delim('['):
+			if token == "-" {
+				if i != len(tokens)-1 {
+					return fmt.Errorf("%w %q: \"-\" must be the last reference token", ErrInvalidPointer, ptr)
+				}
+
+				return nil
+			}
+
+			index, ok := parseArrayIndex(token)
+			if !ok {
+				return fmt.Errorf("%w %q: %q is not an array index", ErrInvalidPointer, ptr, token)
+			}
+
+			found, err := d.seekElement(index)
+			if err != nil {
+				return err
+			}
+
+			if !found {
+				return fmt.Errorf("%w: %q", ErrNotFound, ptr)
+			}
+
+		default:
+			return fmt.Errorf("%w: %q", ErrNotFound, ptr)
+		}
+	}
+
+	return nil
+}
+
+// seekMember skips to the value of the named member of the JSON object the Decoder is at the
+// start of. If there is no such member, the whole object is skipped.
+func (d *Decoder) seekMember(name string) (bool, error) {
+	for d.dec.More() {
+		key, err := d.Token()
+		if err != nil {
+			return false, err
+		}
+
+		if key == name {
+			return true, nil
+		}
+
+		if err := d.skip(); err != nil {
+			return false, err
+		}
+	}
+
+	// The closing '}'
+	_, err := d.Token()
+
+	return false, err
+}
+
+// seekElement skips to the element at index of the JSON array the Decoder is at the start of.
+// If there is no such element, the whole array is skipped.
+func (d *Decoder) seekElement(index int) (bool, error) {
+	for i := 0; d.dec.More(); i++ {
+		if i == index {
+			return true, nil
+		}
+
+		if err := d.skip(); err != nil {
+			return false, err
+		}
+	}
+
+	// The closing ']'
+	_, err := d.Token()
+
+	return false, err
+}
+
+// skip reads past the next value without decoding it.
+func (d *Decoder) skip() error {
+	depth := 0
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// value decodes the next JSON value, keeping JSON object members in order.
+func (d *Decoder) value() (interface{}, error) {
+	tok, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		// A string, number, bool, or null
+		return tok, nil
+	}
+
+	switch delim {
+	case '{':
+		obj := NewObject()
+
+		for d.dec.More() {
+			keyTok, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+
+			obj.set(keyTok.(string), value)
+		}
+
+		// The closing '}'
+		_, err = d.Token()
+
+		return obj, err
+
+	case '[':
+		arr := make([]interface{}, 0)
+
+		for d.dec.More() {
+			value, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+
+			arr = append(arr, value)
+		}
+
+		// The closing ']'
+		_, err = d.Token()
+
+		return arr, err
+	}
+
+	return nil, errors.New("jsonnode: unexpected " + delim.String())
+}
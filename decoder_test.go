@@ -0,0 +1,212 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder(t *testing.T) {
+	t.Parallel()
+
+	// all calls Next until io.EOF, returning the JSON of each value
+	all := func(t *testing.T, dec *Decoder) []string {
+		var values []string
+
+		for {
+			jn, err := dec.Next()
+			if err == io.EOF {
+				return values
+			}
+
+			require.NoError(t, err)
+
+			data, err := json.Marshal(jn)
+			require.NoError(t, err)
+
+			values = append(values, string(data))
+		}
+	}
+
+	const itemsJSON = `{"name": "list", "skip": {"items": [0]}, "items": [1, {"b": 2, "a": [3]}, "four"], "after": true}`
+
+	t.Run("seek array elements", func(t *testing.T) {
+		t.Parallel()
+
+		for _, lazy := range []bool{false, true} {
+			dec := NewDecoderWithOptions(strings.NewReader(itemsJSON), DecoderOptions{Lazy: lazy})
+			require.NoError(t, dec.Seek("/items/-"))
+			require.Equal(t, []string{`1`, `{"b":2,"a":[3]}`, `"four"`}, all(t, dec))
+
+			// The Decoder is past the end of the array, and can carry on
+			tok, err := dec.Token()
+			require.NoError(t, err)
+			require.Equal(t, "after", tok)
+
+			jn, err := dec.Next()
+			require.NoError(t, err)
+			require.Equal(t, true, jn.Value())
+
+			_, err = dec.Next()
+			require.Equal(t, io.EOF, err)
+
+			_, err = dec.Next()
+			require.Equal(t, io.EOF, err)
+		}
+	})
+
+	t.Run("seek", func(t *testing.T) {
+		t.Parallel()
+
+		for ptr, expected := range map[string]string{
+			"":              `{"name":"list","skip":{"items":[0]},"items":[1,{"b":2,"a":[3]},"four"],"after":true}`,
+			"/name":         `"list"`,
+			"/items/1":      `{"b":2,"a":[3]}`,
+			"/items/1/a/0":  `3`,
+			"/items/2":      `"four"`,
+			"/skip/items/0": `0`,
+		} {
+			dec := NewDecoder(strings.NewReader(itemsJSON))
+			require.NoError(t, dec.Seek(ptr), ptr)
+
+			jn, err := dec.Next()
+			require.NoError(t, err, ptr)
+
+			data, err := json.Marshal(jn)
+			require.NoError(t, err, ptr)
+			require.Equal(t, expected, string(data), ptr)
+		}
+	})
+
+	t.Run("seek errors", func(t *testing.T) {
+		t.Parallel()
+
+		for ptr, expected := range map[string]error{
+			"/missing":     ErrNotFound,
+			"/items/3":     ErrNotFound,
+			"/name/0":      ErrNotFound,
+			"/items/1/b/c": ErrNotFound,
+			"/items/01":    ErrInvalidPointer,
+			"/items/x":     ErrInvalidPointer,
+			"/items/-/0":   ErrInvalidPointer,
+			"items":        ErrInvalidPointer,
+		} {
+			err := NewDecoder(strings.NewReader(itemsJSON)).Seek(ptr)
+			require.True(t, errors.Is(err, expected), "%q: %v", ptr, err)
+		}
+
+		err := NewDecoder(strings.NewReader(`{"items": [1, 2`)).Seek("/items/5")
+		var syntaxErr *json.SyntaxError
+		require.True(t, errors.As(err, &syntaxErr), "%v", err)
+	})
+
+	t.Run("stream of values", func(t *testing.T) {
+		t.Parallel()
+
+		dec := NewDecoder(strings.NewReader(`{"a": 1} [2] "three" 4 null`))
+		require.Equal(t, []string{`{"a":1}`, `[2]`, `"three"`, `4`, `null`}, all(t, dec))
+
+		dec = NewDecoder(strings.NewReader(`1 ]`))
+		_, err := dec.Next()
+		require.NoError(t, err)
+
+		_, err = dec.Next()
+		require.Error(t, err)
+		require.NotEqual(t, io.EOF, err)
+	})
+
+	t.Run("object members", func(t *testing.T) {
+		t.Parallel()
+
+		dec := NewDecoder(strings.NewReader(`{"a": [1], "b": {"c": 2}}`))
+		tok, err := dec.Token()
+		require.NoError(t, err)
+		require.Equal(t, json.Delim('{'), tok)
+
+		_, err = dec.Next()
+		require.Error(t, err)
+
+		var members []string
+
+		for dec.More() {
+			tok, err := dec.Token()
+			require.NoError(t, err)
+
+			jn, err := dec.Next()
+			require.NoError(t, err)
+
+			data, err := json.Marshal(jn)
+			require.NoError(t, err)
+
+			members = append(members, fmt.Sprintf("%v=%s", tok, data))
+		}
+
+		require.Equal(t, []string{`a=[1]`, `b={"c":2}`}, members)
+		require.Equal(t, []string(nil), all(t, dec))
+
+		_, err = dec.Token()
+		require.Equal(t, io.EOF, err)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		t.Parallel()
+
+		dec := NewDecoder(strings.NewReader(`[1, 2`))
+		require.NoError(t, dec.Seek("/-"))
+
+		_, err := dec.Next()
+		require.NoError(t, err)
+
+		_, err = dec.Next()
+		require.NoError(t, err)
+
+		_, err = dec.Next()
+		var syntaxErr *json.SyntaxError
+		require.True(t, errors.As(err, &syntaxErr), "%v", err)
+	})
+
+	t.Run("use number", func(t *testing.T) {
+		t.Parallel()
+
+		for _, lazy := range []bool{false, true} {
+			dec := NewDecoderWithOptions(strings.NewReader(`[1.50]`), DecoderOptions{UseNumber: true, Lazy: lazy})
+			require.NoError(t, dec.Seek("/0"))
+
+			jn, err := dec.Next()
+			require.NoError(t, err)
+			require.Equal(t, json.Number("1.50"), jn.Value())
+		}
+	})
+}
+
+func ExampleDecoder_Seek() {
+	r := strings.NewReader(`{"count": 3, "items": [{"name": "cheddar"}, {"name": "brie"}, {"name": "gouda"}]}`)
+
+	dec := NewDecoder(r)
+	if err := dec.Seek("/items/-"); err != nil {
+		panic(err)
+	}
+
+	for {
+		item, err := dec.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Println(item.Get("name").Value())
+	}
+
+	// Output:
+	// cheddar
+	// brie
+	// gouda
+}
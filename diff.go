@@ -0,0 +1,204 @@
+package jsonnode
+
+import "strconv"
+
+// DiffOptions controls how a JSON Patch is generated by DiffWithOptions.
+type DiffOptions struct {
+	// ArrayLCS compares JSON arrays using their longest common subsequence, so inserting or removing
+	// elements in the middle of an array results in a single "add" or "remove" operation rather
+	// than replacing every element that follows. This takes time and memory proportional to the
+	// product of the lengths of the arrays (after any common prefix and suffix are skipped).
+	// Without it, arrays are compared element by element.
+	ArrayLCS bool
+}
+
+// Diff generates a JSON Patch (RFC 6902) that turns a into b.
+// Applying the patch to a (or a copy of it) with ApplyPatch will result in the same JSON as b.
+func Diff(a, b *JSONNode) (Patch, error) {
+	return DiffWithOptions(a, b, DiffOptions{})
+}
+
+// DiffWithOptions is like Diff, but allows controlling how the patch is generated.
+func DiffWithOptions(a, b *JSONNode, opts DiffOptions) (Patch, error) {
+	if a == nil || b == nil {
+		return nil, ErrNilNode
+	}
+
+	// An empty patch rather than nil, which would be marshalled as null
+	d := &differ{opts: opts, patch: Patch{}, cmp: newComparer(defaultEqualOptions)}
+	d.diff("", a.OrderedValue(), b.OrderedValue())
+
+	return d.patch, nil
+}
+
+type differ struct {
+	opts  DiffOptions
+	patch Patch
+	cmp   *comparer
+}
+
+func (d *differ) equal(a, b interface{}) bool {
+	return d.cmp.equal(a, b, "")
+}
+
+// hashes gets the hash of each element of an array (see Hash).
+func (d *differ) hashes(values []interface{}) []uint64 {
+	hashes := make([]uint64, len(values))
+	for i := range values {
+		hashes[i] = d.cmp.hash(values[i], "")
+	}
+
+	return hashes
+}
+
+func (d *differ) add(op, path string, value interface{}) {
+	operation := Operation{Op: op, Path: path}
+
+	if op != OpRemove {
+		operation.Value = copyValue(value)
+	}
+
+	d.patch = append(d.patch, operation)
+}
+
+func (d *differ) diff(path string, a, b interface{}) {
+	if d.equal(a, b) {
+		return
+	}
+
+	switch aVal := a.(type) {
+	case *Object:
+		if bVal, ok := b.(*Object); ok {
+			d.diffObjects(path, aVal, bVal)
+			return
+		}
+
+	case []interface{}:
+		if bVal, ok := b.([]interface{}); ok {
+			if d.opts.ArrayLCS {
+				d.diffArraysLCS(path, aVal, bVal)
+			} else {
+				d.diffArrays(path, aVal, bVal)
+			}
+
+			return
+		}
+	}
+
+	d.add(OpReplace, path, b)
+}
+
+func (d *differ) diffObjects(path string, a, b *Object) {
+	for _, key := range a.keys {
+		memberPath := path + "/" + escapePointerToken(key)
+
+		bVal, ok := b.Get(key)
+		if !ok {
+			d.add(OpRemove, memberPath, nil)
+			continue
+		}
+
+		d.diff(memberPath, a.values[key], bVal)
+	}
+
+	for _, key := range b.keys {
+		if _, ok := a.Get(key); !ok {
+			d.add(OpAdd, path+"/"+escapePointerToken(key), b.values[key])
+		}
+	}
+}
+
+// diffArrays compares arrays element by element.
+func (d *differ) diffArrays(path string, a, b []interface{}) {
+	common := len(a)
+	if len(b) < common {
+		common = len(b)
+	}
+
+	for i := 0; i < common; i++ {
+		d.diff(path+"/"+strconv.Itoa(i), a[i], b[i])
+	}
+
+	// Remove from the end so the indexes don't shift
+	for i := len(a) - 1; i >= common; i-- {
+		d.add(OpRemove, path+"/"+strconv.Itoa(i), nil)
+	}
+
+	for i := common; i < len(b); i++ {
+		d.add(OpAdd, path+"/"+strconv.Itoa(i), b[i])
+	}
+}
+
+// diffArraysLCS compares arrays using their longest common subsequence.
+func (d *differ) diffArraysLCS(path string, a, b []interface{}) {
+	// Skip anything that's the same at the start and end
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && d.equal(a[prefix], b[prefix]) {
+		prefix++
+	}
+
+	a, b = a[prefix:], b[prefix:]
+
+	for len(a) > 0 && len(b) > 0 && d.equal(a[len(a)-1], b[len(b)-1]) {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	// Elements are compared in full only if their hashes match
+	aHashes, bHashes := d.hashes(a), d.hashes(b)
+	equal := func(i, j int) bool {
+		return aHashes[i] == bHashes[j] && d.equal(a[i], b[j])
+	}
+
+	// lcs(i, j) is the length of the longest common subsequence of a[i:] and b[j:]. The table is
+	// kept in one slice, of int32 to halve its size.
+	width := len(b) + 1
+	table := make([]int32, (len(a)+1)*width)
+	lcs := func(i, j int) int32 {
+		return table[i*width+j]
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case equal(i, j):
+				table[i*width+j] = lcs(i+1, j+1) + 1
+
+			case lcs(i+1, j) >= lcs(i, j+1):
+				table[i*width+j] = lcs(i+1, j)
+
+			default:
+				table[i*width+j] = lcs(i, j+1)
+			}
+		}
+	}
+
+	// index is where we are in the array as it will be after the operations so far are applied
+	i, j, index := 0, 0, prefix
+
+	for i < len(a) || j < len(b) {
+		elemPath := path + "/" + strconv.Itoa(index)
+
+		switch {
+		case i < len(a) && j < len(b) && equal(i, j):
+			i++
+			j++
+			index++
+
+		case i < len(a) && j < len(b) && lcs(i, j) == lcs(i+1, j+1):
+			// Neither element is part of the common subsequence, so change one into the other
+			d.diff(elemPath, a[i], b[j])
+			i++
+			j++
+			index++
+
+		case j < len(b) && (i == len(a) || lcs(i, j+1) >= lcs(i+1, j)):
+			d.add(OpAdd, elemPath, b[j])
+			j++
+			index++
+
+		default:
+			d.add(OpRemove, elemPath, nil)
+			i++
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	// checkPatch makes sure the patch turns a into b
+	checkPatch := func(t *testing.T, a, b string, patch Patch) {
+		jn := unmarshalString(t, a)
+
+		err := jn.ApplyPatch(patch)
+		require.NoError(t, err)
+
+		data, err := json.Marshal(jn)
+		require.NoError(t, err)
+		require.JSONEq(t, b, string(data))
+	}
+
+	pairs := []struct {
+		a, b string
+	}{
+		{`{}`, `{}`},
+		{`{"a": 1}`, `{"a": 2}`},
+		{`{"a": 1}`, `{"b": 1}`},
+		{`{"a": {"b": [1, 2, 3]}}`, `{"a": {"b": [1, 3]}}`},
+		{`{"a": [1, 2, 3]}`, `{"a": [0, 1, 2, 3, 4]}`},
+		{`{"a": [1, 2, 3]}`, `{"a": [3, 2, 1]}`},
+		{`{"a": [1, 2, 3]}`, `{"a": []}`},
+		{`{"a": []}`, `{"a": [[1], {"b": null}]}`},
+		{`{"a": [{"id": 1}, {"id": 2}]}`, `{"a": [{"id": 2, "x": true}]}`},
+		{`{"a": "string"}`, `{"a": ["array"]}`},
+		{`{"a~b/c": 1}`, `{"a~b/c": 2, "": 3}`},
+		{`[1, 2]`, `{"a": 1}`},
+		{`null`, `1`},
+		{platterJSON, `{
+    "platter": "wood",
+    "cheeses": ["cheddar", "gouda", "swiss"],
+    "with": {
+        "fruit": [{"type": "figs", "count": 2}, {"type": "grapes", "count": 9}],
+        "bread": "baguette"
+    }
+}`},
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		for _, opts := range []DiffOptions{{}, {ArrayLCS: true}} {
+			for _, pair := range pairs {
+				patch, err := DiffWithOptions(unmarshalString(t, pair.a), unmarshalString(t, pair.b), opts)
+				require.NoError(t, err)
+
+				checkPatch(t, pair.a, pair.b, patch)
+
+				// And the other way
+				patch, err = DiffWithOptions(unmarshalString(t, pair.b), unmarshalString(t, pair.a), opts)
+				require.NoError(t, err)
+
+				checkPatch(t, pair.b, pair.a, patch)
+			}
+		}
+	})
+
+	t.Run("minimal", func(t *testing.T) {
+		t.Parallel()
+
+		patch, err := Diff(unmarshalString(t, platterJSON), unmarshalString(t, platterJSON))
+		require.NoError(t, err)
+		require.Empty(t, patch)
+
+		patch, err = Diff(unmarshalString(t, `{"a": 1, "b": {"c": "d"}, "e": 2}`), unmarshalString(t, `{"b": {"c": "x"}, "e": 2, "f": 3}`))
+		require.NoError(t, err)
+		require.Equal(t, Patch{
+			{Op: OpRemove, Path: "/a"},
+			{Op: OpReplace, Path: "/b/c", Value: "x"},
+			{Op: OpAdd, Path: "/f", Value: float64(3)},
+		}, patch)
+
+		patch, err = Diff(unmarshalString(t, `{"a": [1, 2, 3]}`), unmarshalString(t, `{"a": [1]}`))
+		require.NoError(t, err)
+		require.Equal(t, Patch{
+			{Op: OpRemove, Path: "/a/2"},
+			{Op: OpRemove, Path: "/a/1"},
+		}, patch)
+	})
+
+	t.Run("lcs", func(t *testing.T) {
+		t.Parallel()
+
+		a := unmarshalString(t, platterJSON)
+		b := unmarshalString(t, platterJSON)
+		require.NoError(t, b.Get("cheeses").InsertAt(1, "gouda"))
+
+		// Without LCS, everything after the new cheese is replaced
+		patch, err := Diff(a, b)
+		require.NoError(t, err)
+		require.Len(t, patch, 3)
+
+		patch, err = DiffWithOptions(a, b, DiffOptions{ArrayLCS: true})
+		require.NoError(t, err)
+		require.Equal(t, Patch{{Op: OpAdd, Path: "/cheeses/1", Value: "gouda"}}, patch)
+
+		// Removing from the middle
+		patch, err = DiffWithOptions(b, a, DiffOptions{ArrayLCS: true})
+		require.NoError(t, err)
+		require.Equal(t, Patch{{Op: OpRemove, Path: "/cheeses/1"}}, patch)
+
+		// Changes inside an element that isn't otherwise moving
+		patch, err = DiffWithOptions(
+			unmarshalString(t, `[1, {"a": 1, "b": 2}, 3]`),
+			unmarshalString(t, `[1, {"a": 1, "b": 3}, 3, 4]`),
+			DiffOptions{ArrayLCS: true},
+		)
+		require.NoError(t, err)
+		require.Equal(t, Patch{
+			{Op: OpReplace, Path: "/1/b", Value: float64(3)},
+			{Op: OpAdd, Path: "/3", Value: float64(4)},
+		}, patch)
+	})
+
+	t.Run("patch values are copies", func(t *testing.T) {
+		t.Parallel()
+
+		b := unmarshalString(t, `{"a": {"b": 1}}`)
+
+		patch, err := Diff(New(), b)
+		require.NoError(t, err)
+		require.Len(t, patch, 1)
+
+		require.NoError(t, b.Get("a").Set("b", float64(2)))
+
+		data, err := json.Marshal(patch[0].Value)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"b": 1}`, string(data))
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		t.Parallel()
+
+		patch, err := Diff(unmarshalString(t, platterJSON), unmarshalString(t, platterJSON))
+		require.NoError(t, err)
+		require.Equal(t, Patch{}, patch)
+
+		data, err := json.Marshal(patch)
+		require.NoError(t, err)
+		require.Equal(t, `[]`, string(data))
+
+		_, err = ParsePatch(data)
+		require.NoError(t, err)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Diff(nil, New())
+		require.Equal(t, ErrNilNode, err)
+	})
+}
+
+func BenchmarkDiffArrayLCS(b *testing.B) {
+	items := make([]interface{}, 5000)
+	for i := range items {
+		items[i] = map[string]interface{}{"id": i, "name": fmt.Sprintf("item %d", i)}
+	}
+
+	a := NewFromValue(items)
+
+	// One element inserted near the start, and one removed near the end
+	other := NewFromValue(items)
+	require.NoError(b, other.InsertAt(10, map[string]interface{}{"id": -1}))
+	require.NoError(b, other.RemoveAt(4990))
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		patch, err := DiffWithOptions(a, other, DiffOptions{ArrayLCS: true})
+		require.NoError(b, err)
+		require.Len(b, patch, 2)
+	}
+}
@@ -0,0 +1,437 @@
+package jsonnode
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+var (
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// maxEncodeDepth is how deeply nested a value can be before FromValue assumes it has a cycle.
+const maxEncodeDepth = 1000
+
+// maxExactInt is the largest integer a float64 can hold exactly (2^53).
+const maxExactInt = 1 << 53
+
+// FromValue creates a new JSONNode from any Go value that can be marshalled by encoding/json,
+// following the same rules (including json struct tags, and types that implement json.Marshaler or
+// encoding.TextMarshaler).
+// The value is converted into the types a JSONNode holds, without going through JSON. Integers are
+// float64 if they can be held exactly, or json.Number otherwise. Numbers marshalled by a
+// json.Marshaler are float64 if the float64 is the same number (so 0.1 is, but 12345678901234567890
+// isn't), or json.Number otherwise.
+func FromValue(v interface{}) (*JSONNode, error) {
+	value, err := toValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	jn := new(JSONNode)
+	jn.init()
+	jn.data = value
+
+	return jn, nil
+}
+
+// toValue converts a Go value into the types a JSONNode holds.
+// Values that are already made up of those types are copied.
+func toValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+
+	case string, bool, json.Number:
+		return v, nil
+
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, &json.UnsupportedValueError{Value: reflect.ValueOf(v), Str: strconv.FormatFloat(v, 'g', -1, 64)}
+		}
+
+		return v, nil
+
+	case *JSONNode:
+		if v == nil {
+			return nil, nil
+		}
+
+		return copyValue(v.OrderedValue()), nil
+
+	case *Immutable:
+		if v == nil {
+			return nil, nil
+		}
+
+		return copyValue(v.OrderedValue()), nil
+	}
+
+	e := &valueEncoder{}
+
+	return e.encode(reflect.ValueOf(v))
+}
+
+// valueEncoder converts Go values into the types a JSONNode holds.
+type valueEncoder struct {
+	depth int
+}
+
+func (e *valueEncoder) encode(v reflect.Value) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	if value, ok, err := e.marshaler(v); ok {
+		return value, err
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		return e.nested(v)
+
+	case reflect.Bool:
+		return v.Bool(), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := v.Int()
+		if i < -maxExactInt || i > maxExactInt {
+			return json.Number(strconv.FormatInt(i, 10)), nil
+		}
+
+		return float64(i), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := v.Uint()
+		if u > maxExactInt {
+			return json.Number(strconv.FormatUint(u, 10)), nil
+		}
+
+		return float64(u), nil
+
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, &json.UnsupportedValueError{Value: v, Str: strconv.FormatFloat(f, 'g', -1, v.Type().Bits())}
+		}
+
+		if v.Kind() == reflect.Float32 {
+			// Use the float64 closest to how the float32 would be marshalled, so 0.1 stays 0.1
+			f, _ = strconv.ParseFloat(strconv.FormatFloat(f, 'g', -1, 32), 64)
+		}
+
+		return f, nil
+
+	case reflect.String:
+		if v.Type() == numberType {
+			return json.Number(v.String()), nil
+		}
+
+		return v.String(), nil
+
+	case reflect.Struct:
+		return e.structValue(v)
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		return e.nested(v)
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		if v.Type().Elem().Kind() == reflect.Uint8 && !reflect.PtrTo(v.Type().Elem()).Implements(marshalerType) &&
+			!reflect.PtrTo(v.Type().Elem()).Implements(textMarshalerType) {
+			// []byte is base64 encoded
+			return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+		}
+
+		return e.nested(v)
+
+	case reflect.Array:
+		return e.array(v)
+	}
+
+	return nil, &json.UnsupportedTypeError{Type: v.Type()}
+}
+
+// nested encodes what v holds (a map, slice, or pointer), keeping track of how deeply nested it is
+// so that cycles are found.
+func (e *valueEncoder) nested(v reflect.Value) (interface{}, error) {
+	e.depth++
+	defer func() { e.depth-- }()
+
+	if e.depth > maxEncodeDepth {
+		return nil, &json.UnsupportedValueError{Value: v, Str: "encountered a cycle via " + v.Type().String()}
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		return e.mapValue(v)
+
+	case reflect.Slice:
+		return e.array(v)
+	}
+
+	return e.encode(v.Elem())
+}
+
+// marshaler uses json.Marshaler or encoding.TextMarshaler, if v implements either of them.
+// ok is false if it doesn't.
+func (e *valueEncoder) marshaler(v reflect.Value) (value interface{}, ok bool, err error) {
+	if v.Kind() == reflect.Interface || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return nil, false, nil
+	}
+
+	t := v.Type()
+
+	if t.Kind() != reflect.Ptr && v.CanAddr() && !t.Implements(marshalerType) && !t.Implements(textMarshalerType) &&
+		(reflect.PtrTo(t).Implements(marshalerType) || reflect.PtrTo(t).Implements(textMarshalerType)) {
+		// The methods have pointer receivers
+		v = v.Addr()
+		t = v.Type()
+	}
+
+	switch {
+	case t.Implements(marshalerType):
+		// These don't need to go through JSON
+		switch m := v.Interface().(type) {
+		case *JSONNode:
+			return copyValue(m.OrderedValue()), true, nil
+
+		case *Immutable:
+			return copyValue(m.OrderedValue()), true, nil
+
+		case *Object:
+			return copyValue(m), true, nil
+		}
+
+		data, err := v.Interface().(json.Marshaler).MarshalJSON()
+		if err != nil {
+			return nil, true, &json.MarshalerError{Type: t, Err: err}
+		}
+
+		// Numbers are decoded as json.Number first, so none of their digits are lost
+		value, err := decode(data, DecoderOptions{UseNumber: true})
+		if err != nil {
+			return nil, true, &json.MarshalerError{Type: t, Err: err}
+		}
+
+		return floatNumbers(value), true, nil
+
+	case t.Implements(textMarshalerType):
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, true, &json.MarshalerError{Type: t, Err: err}
+		}
+
+		return string(text), true, nil
+	}
+
+	return nil, false, nil
+}
+
+func (e *valueEncoder) structValue(v reflect.Value) (interface{}, error) {
+	fields := cachedTypeFields(v.Type())
+	obj := &Object{
+		keys:   make([]string, 0, len(fields.list)),
+		values: make(map[string]interface{}, len(fields.list)),
+	}
+
+fields:
+	for i := range fields.list {
+		f := &fields.list[i]
+
+		fieldValue := v
+		for _, index := range f.index {
+			if fieldValue.Kind() == reflect.Ptr {
+				if fieldValue.IsNil() {
+					// The field is in an embedded struct pointer that is nil
+					continue fields
+				}
+
+				fieldValue = fieldValue.Elem()
+			}
+
+			fieldValue = fieldValue.Field(index)
+		}
+
+		if f.omitEmpty && isEmptyValue(fieldValue) {
+			continue
+		}
+
+		value, err := e.encode(fieldValue)
+		if err != nil {
+			return nil, err
+		}
+
+		if f.quoted {
+			value, err = quote(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		obj.keys = append(obj.keys, f.name)
+		obj.values[f.name] = value
+	}
+
+	return obj, nil
+}
+
+// quote implements the ",string" tag option, which puts a scalar value into a JSON string as JSON.
+func quote(value interface{}) (interface{}, error) {
+	switch value.(type) {
+	case string, float64, json.Number, bool:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		return string(data), nil
+	}
+
+	// Anything else (such as a nil pointer) is left alone, like encoding/json does
+	return value, nil
+}
+
+func (e *valueEncoder) mapValue(v reflect.Value) (interface{}, error) {
+	keys := make([]string, 0, v.Len())
+	values := make(map[string]interface{}, v.Len())
+
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKey(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := e.encode(iter.Value())
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+		values[key] = value
+	}
+
+	// Like encoding/json, map keys are sorted
+	sort.Strings(keys)
+
+	return &Object{keys: keys, values: values}, nil
+}
+
+// mapKey gets the JSON object member name for a map key, following the rules of encoding/json.
+func mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Ptr && k.IsNil() {
+			return "", nil
+		}
+
+		text, err := tm.MarshalText()
+		if err != nil {
+			return "", &json.MarshalerError{Type: k.Type(), Err: err}
+		}
+
+		return string(text), nil
+	}
+
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+
+	return "", &json.UnsupportedTypeError{Type: k.Type()}
+}
+
+func (e *valueEncoder) array(v reflect.Value) (interface{}, error) {
+	arr := make([]interface{}, v.Len())
+
+	for i := range arr {
+		value, err := e.encode(v.Index(i))
+		if err != nil {
+			return nil, err
+		}
+
+		arr[i] = value
+	}
+
+	return arr, nil
+}
+
+// isEmptyValue reports whether v is empty, for the "omitempty" tag option.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+
+	case reflect.Bool:
+		return !v.Bool()
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+
+	return false
+}
+
+// floatNumbers converts the json.Numbers in a newly decoded value to float64, in place, if they can
+// be held by a float64 without changing them. The others are kept as json.Number.
+func floatNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			return v
+		}
+
+		literal, ok := canonicalDecimal(v)
+		if float, _ := canonicalDecimal(numberLiteral(f)); !ok || float != literal {
+			return v
+		}
+
+		return f
+
+	case *Object:
+		for key, member := range v.values {
+			v.values[key] = floatNumbers(member)
+		}
+
+	case []interface{}:
+		for i := range v {
+			v[i] = floatNumbers(v[i])
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,198 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type encodeOwner struct {
+	ID    uint64 `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+type encodeTarget struct {
+	decodeEmbedded
+	*DecodePointer
+
+	Owner     *encodeOwner       `json:"owner"`
+	Shadowed  int                `json:"Shadowed"`
+	Skipped   string             `json:"-"`
+	Quoted    int64              `json:"quoted,string"`
+	QuotedStr string             `json:",string"`
+	Empty     []int              `json:"empty,omitempty"`
+	NilSlice  []int              `json:"nil_slice"`
+	Array     [2]float32         `json:"array"`
+	Map       map[string]int     `json:"map"`
+	IntMap    map[int]string     `json:"int_map"`
+	Bytes     []byte             `json:"bytes"`
+	Time      time.Time          `json:"time"`
+	IP        net.IP             `json:"ip"`
+	Number    json.Number        `json:"number"`
+	Raw       json.RawMessage    `json:"raw"`
+	Node      *JSONNode          `json:"node"`
+	Any       interface{}        `json:"any"`
+	Nested    []map[string][]int `json:"nested"`
+}
+
+func TestFromValue(t *testing.T) {
+	t.Parallel()
+
+	node := new(JSONNode)
+	require.NoError(t, json.Unmarshal([]byte(`{"b": 1, "a": [true]}`), node))
+
+	values := []interface{}{
+		nil,
+		"str",
+		42,
+		int8(-3),
+		uint64(math.MaxUint64),
+		int64(math.MinInt64),
+		float32(0.1),
+		1.5,
+		[]string{"a", "b"},
+		map[string]interface{}{"z": 1, "a": []interface{}{nil, "x"}},
+		encodeOwner{ID: 1, Name: "owner"},
+		&encodeTarget{
+			decodeEmbedded: decodeEmbedded{Embedded: "promoted", Shadowed: "hidden"},
+			DecodePointer:  &DecodePointer{Name: "pointer"},
+			Owner:          &encodeOwner{ID: 9007199254740993, Name: "big"},
+			Shadowed:       3,
+			Skipped:        "skipped",
+			Quoted:         9007199254740993,
+			QuotedStr:      "inner",
+			Array:          [2]float32{0.1, 2},
+			Map:            map[string]int{"y": 2, "x": 1},
+			IntMap:         map[int]string{10: "ten", 2: "two"},
+			Bytes:          []byte("hello"),
+			Time:           time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC),
+			IP:             net.ParseIP("192.0.2.1"),
+			Number:         "1.10",
+			Raw:            json.RawMessage(`{"raw": [1, 2]}`),
+			Node:           node,
+			Any:            []interface{}{map[string]string{"k": "v"}},
+			Nested:         []map[string][]int{{"n": {1, 2}}},
+		},
+		encodeTarget{},
+	}
+
+	for _, value := range values {
+		expected, err := json.Marshal(value)
+		require.NoError(t, err)
+
+		jn, err := FromValue(value)
+		require.NoError(t, err, "%#v", value)
+
+		actual, err := json.Marshal(jn)
+		require.NoError(t, err)
+
+		require.Equal(t, string(expected), string(actual))
+	}
+}
+
+func TestFromValueNumbers(t *testing.T) {
+	t.Parallel()
+
+	bigInt, ok := new(big.Int).SetString("123456789012345678901234567890", 10)
+	require.True(t, ok)
+
+	jn, err := FromValue(map[string]interface{}{
+		"small":   42,
+		"big":     int64(9007199254740993),
+		"float32": float32(0.1),
+		"bigInt":  bigInt,
+		"raw":     json.RawMessage(`[12345678901234567890, 1.10, 0.1, 1e2]`),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, float64(42), jn.Get("small").Value())
+	require.Equal(t, json.Number("9007199254740993"), jn.Get("big").Value())
+	require.Equal(t, 0.1, jn.Get("float32").Value())
+
+	// Numbers from a json.Marshaler keep all of their digits
+	require.Equal(t, json.Number("123456789012345678901234567890"), jn.Get("bigInt").Value())
+	require.Equal(t, []interface{}{json.Number("12345678901234567890"), 1.1, 0.1, 100.0}, jn.Get("raw").Value())
+
+	id, err := jn.Int64("big")
+	require.NoError(t, err)
+	require.Equal(t, int64(9007199254740993), id)
+}
+
+func TestFromValueErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := FromValue(make(chan int))
+	var typeErr *json.UnsupportedTypeError
+	require.True(t, errors.As(err, &typeErr), "%v", err)
+
+	_, err = FromValue(math.NaN())
+	var valueErr *json.UnsupportedValueError
+	require.True(t, errors.As(err, &valueErr), "%v", err)
+
+	type cycle struct {
+		Next *cycle
+	}
+
+	c := &cycle{}
+	c.Next = c
+
+	_, err = FromValue(c)
+	require.True(t, errors.As(err, &valueErr), "%v", err)
+
+	_, err = FromValue(failingMarshaler{})
+	var marshalerErr *json.MarshalerError
+	require.True(t, errors.As(err, &marshalerErr), "%v", err)
+}
+
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("nope")
+}
+
+func TestJSONNodeSetStruct(t *testing.T) {
+	t.Parallel()
+
+	jn := New()
+
+	owner := &encodeOwner{ID: 7, Name: "someone"}
+	require.NoError(t, jn.Set("owner", owner))
+
+	// It's stored as JSON data, not as the struct
+	ownerNode, ok := jn.Get("owner").ValueAsNode()
+	require.True(t, ok)
+	require.Equal(t, []string{"id", "name"}, ownerNode.Keys())
+
+	name, err := jn.String("owner", "name")
+	require.NoError(t, err)
+	require.Equal(t, "someone", name)
+
+	// Changing the struct afterwards doesn't change the node
+	owner.Name = "someone else"
+	name, err = jn.String("owner", "name")
+	require.NoError(t, err)
+	require.Equal(t, "someone", name)
+
+	require.NoError(t, jn.Set("owners", []encodeOwner{*owner}))
+	require.NoError(t, jn.Get("owners").Append(encodeOwner{ID: 8}))
+	require.NoError(t, jn.Get("owners").InsertAt(0, map[string]int{"id": 6}))
+	require.NoError(t, jn.SetPointer("/owners/1/email", "e@example.com"))
+	require.NoError(t, jn.ApplyPatch(Patch{{Op: OpAdd, Path: "/count", Value: uint8(3)}}))
+
+	require.JSONEq(t, `{
+		"owner": {"id": 7, "name": "someone"},
+		"owners": [{"id": 6}, {"id": 7, "name": "someone else", "email": "e@example.com"}, {"id": 8, "name": ""}],
+		"count": 3
+	}`, marshal(t, jn))
+
+	require.Error(t, jn.Set("bad", func() {}))
+	require.Error(t, jn.Get("owners").Append(make(chan int)))
+	require.Nil(t, jn.Get("bad"))
+}
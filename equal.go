@@ -0,0 +1,305 @@
+package jsonnode
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// EqualOptions controls how EqualWithOptions compares JSON values.
+// The zero value is the strictest comparison.
+type EqualOptions struct {
+	// NumbersByValue compares numbers by their value, so 1, 1.0 and 1e0 are equal. Otherwise numbers
+	// must be written the same way, which only makes a difference for numbers decoded with
+	// DecoderOptions.UseNumber.
+	NumbersByValue bool
+
+	// IgnoreKeyOrder compares JSON objects without regard to the order of their members.
+	IgnoreKeyOrder bool
+
+	// ArraysAsMultisets compares JSON arrays without regard to order. They are equal if they have
+	// the same elements the same number of times.
+	ArraysAsMultisets bool
+
+	// IgnorePaths are JSON Pointers (RFC 6901) to values that are left out of the comparison, such as
+	// "/updated_at". They are relative to the nodes being compared.
+	IgnorePaths []string
+}
+
+// defaultEqualOptions compare JSON values the way the JSON spec (RFC 8259) sees them.
+var defaultEqualOptions = EqualOptions{NumbersByValue: true, IgnoreKeyOrder: true}
+
+// Equal reports whether a and b hold the same JSON. Members of JSON objects can be in any order,
+// and numbers are compared by value. Nodes that don't exist are only equal to each other.
+func Equal(a, b *JSONNode) bool {
+	return EqualWithOptions(a, b, defaultEqualOptions)
+}
+
+// EqualWithOptions is like Equal, as controlled by opts.
+func EqualWithOptions(a, b *JSONNode, opts EqualOptions) bool {
+	aVal, aOK := a.lookup()
+	bVal, bOK := b.lookup()
+
+	if !aOK || !bOK {
+		return aOK == bOK
+	}
+
+	return newComparer(opts).equal(aVal, bVal, "")
+}
+
+// Hash gets a 64-bit hash of the JSON held by this node. Nodes that are Equal have the same hash.
+// The hash doesn't change between processes or versions of Go, so it can be stored.
+func (jn *JSONNode) Hash() uint64 {
+	return jn.HashWithOptions(defaultEqualOptions)
+}
+
+// HashWithOptions is like Hash, but nodes that are equal according to EqualWithOptions (with the
+// same opts) have the same hash.
+func (jn *JSONNode) HashWithOptions(opts EqualOptions) uint64 {
+	value, ok := jn.lookup()
+	if !ok {
+		return hashBytes([]byte{'m'})
+	}
+
+	return newComparer(opts).hash(value, "")
+}
+
+// comparer compares and hashes JSON values.
+type comparer struct {
+	opts EqualOptions
+
+	// ignore is the JSON pointers in opts.IgnorePaths. If it's empty, paths aren't worked out.
+	ignore map[string]bool
+}
+
+func newComparer(opts EqualOptions) *comparer {
+	c := &comparer{opts: opts}
+
+	if len(opts.IgnorePaths) > 0 {
+		c.ignore = make(map[string]bool, len(opts.IgnorePaths))
+		for _, path := range opts.IgnorePaths {
+			c.ignore[path] = true
+		}
+	}
+
+	return c
+}
+
+// childPath gets the path of a child, if paths are needed.
+func (c *comparer) childPath(path string, token string) string {
+	if c.ignore == nil {
+		return ""
+	}
+
+	return path + "/" + token
+}
+
+// keys gets the keys of obj that aren't ignored.
+func (c *comparer) keys(obj *Object, path string) []string {
+	if c.ignore == nil {
+		return obj.keys
+	}
+
+	keys := make([]string, 0, len(obj.keys))
+	for _, key := range obj.keys {
+		if !c.ignore[path+"/"+escapePointerToken(key)] {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+func (c *comparer) equal(a, b interface{}, path string) bool {
+	switch aVal := a.(type) {
+	case *Object:
+		bVal, ok := b.(*Object)
+		if !ok {
+			return false
+		}
+
+		aKeys, bKeys := c.keys(aVal, path), c.keys(bVal, path)
+		if len(aKeys) != len(bKeys) {
+			return false
+		}
+
+		for i, key := range aKeys {
+			if !c.opts.IgnoreKeyOrder && bKeys[i] != key {
+				return false
+			}
+
+			other, ok := bVal.values[key]
+			if !ok || !c.equal(aVal.values[key], other, c.childPath(path, escapePointerToken(key))) {
+				return false
+			}
+		}
+
+		return true
+
+	case []interface{}:
+		bVal, ok := b.([]interface{})
+		if !ok || len(aVal) != len(bVal) {
+			return false
+		}
+
+		if c.opts.ArraysAsMultisets {
+			return c.equalMultiset(aVal, bVal, path)
+		}
+
+		for i := range aVal {
+			if !c.equal(aVal[i], bVal[i], c.childPath(path, strconv.Itoa(i))) {
+				return false
+			}
+		}
+
+		return true
+
+	case float64, json.Number:
+		switch b.(type) {
+		case float64, json.Number:
+			if c.opts.NumbersByValue {
+				return equalNumbers(a, b)
+			}
+
+			return numberLiteral(a) == numberLiteral(b)
+		}
+
+		return false
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// equalMultiset reports whether two arrays of the same length have the same elements, in any order.
+func (c *comparer) equalMultiset(a, b []interface{}, path string) bool {
+	// Group the elements of b by hash, so each element of a only needs comparing to likely matches
+	buckets := make(map[uint64][]int, len(b))
+	for i := range b {
+		h := c.hash(b[i], c.childPath(path, strconv.Itoa(i)))
+		buckets[h] = append(buckets[h], i)
+	}
+
+	for i := range a {
+		aPath := c.childPath(path, strconv.Itoa(i))
+		h := c.hash(a[i], aPath)
+
+		bucket := buckets[h]
+
+		found := -1
+		for j, bi := range bucket {
+			if c.equal(a[i], b[bi], aPath) {
+				found = j
+				break
+			}
+		}
+
+		if found < 0 {
+			return false
+		}
+
+		buckets[h] = append(bucket[:found:found], bucket[found+1:]...)
+	}
+
+	return true
+}
+
+// hash hashes a value so that values that are equal have the same hash.
+func (c *comparer) hash(value interface{}, path string) uint64 {
+	var buf []byte
+
+	switch v := value.(type) {
+	case nil:
+		buf = []byte{'n'}
+
+	case bool:
+		if v {
+			buf = []byte{'t'}
+		} else {
+			buf = []byte{'f'}
+		}
+
+	case string:
+		buf = append([]byte{'s'}, v...)
+
+	case float64, json.Number:
+		buf = append([]byte{'d'}, c.canonicalNumber(v)...)
+
+	case []interface{}:
+		hashes := make([]uint64, len(v))
+		for i := range v {
+			hashes[i] = c.hash(v[i], c.childPath(path, strconv.Itoa(i)))
+		}
+
+		if c.opts.ArraysAsMultisets {
+			sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+		}
+
+		buf = appendHashes([]byte{'a'}, hashes)
+
+	case *Object:
+		keys := c.keys(v, path)
+		hashes := make([]uint64, len(keys))
+
+		for i, key := range keys {
+			member := c.hash(v.values[key], c.childPath(path, escapePointerToken(key)))
+
+			memberBuf := make([]byte, 0, len(key)+9)
+			memberBuf = append(memberBuf, key...)
+			memberBuf = append(memberBuf, 0)
+			memberBuf = appendUint64(memberBuf, member)
+
+			hashes[i] = hashBytes(memberBuf)
+		}
+
+		if c.opts.IgnoreKeyOrder {
+			sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+		}
+
+		buf = appendHashes([]byte{'o'}, hashes)
+
+	default:
+		// Not one of the types used to represent JSON, so go by how it marshals
+		data, _ := json.Marshal(v)
+		buf = append([]byte{'?'}, data...)
+	}
+
+	return hashBytes(buf)
+}
+
+// canonicalNumber gets a representation of a number that is the same for numbers that are equal.
+func (c *comparer) canonicalNumber(n interface{}) string {
+	literal := numberLiteral(n)
+
+	if c.opts.NumbersByValue {
+		if canonical, ok := canonicalDecimal(literal); ok {
+			return canonical
+		}
+	}
+
+	return string(literal)
+}
+
+func hashBytes(buf []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(buf)
+
+	return h.Sum64()
+}
+
+func appendHashes(buf []byte, hashes []uint64) []byte {
+	for _, h := range hashes {
+		buf = appendUint64(buf, h)
+	}
+
+	return buf
+}
+
+func appendUint64(buf []byte, v uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+
+	return append(buf, b[:]...)
+}
@@ -0,0 +1,170 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEqual(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		a, b  string
+		opts  EqualOptions
+		equal bool
+	}{
+		{name: "same", a: platterJSON, b: platterJSON, equal: true},
+		{name: "numbers by value", a: `[1, 1.0, 1e0, 0.1]`, b: `[1.0, 10e-1, 1, 1e-1]`, opts: defaultEqualOptions, equal: true},
+		{name: "numbers by literal", a: `[1]`, b: `[1.0]`},
+		{name: "numbers differ", a: `[1]`, b: `[1.0000000000000000001]`, opts: defaultEqualOptions},
+		{name: "key order ignored", a: `{"a": 1, "b": 2}`, b: `{"b": 2, "a": 1}`, opts: defaultEqualOptions, equal: true},
+		{name: "key order", a: `{"a": 1, "b": 2}`, b: `{"b": 2, "a": 1}`},
+		{name: "missing key", a: `{"a": 1, "b": 2}`, b: `{"a": 1, "c": 2}`, opts: defaultEqualOptions},
+		{name: "array order", a: `[1, 2, 3]`, b: `[3, 1, 2]`, opts: defaultEqualOptions},
+		{name: "multiset", a: `[1, 2, 2, {"a": [3]}]`, b: `[2, {"a": [3]}, 1, 2]`, opts: EqualOptions{ArraysAsMultisets: true}, equal: true},
+		{name: "multiset counts", a: `[1, 2, 2]`, b: `[1, 1, 2]`, opts: EqualOptions{ArraysAsMultisets: true}},
+		{
+			name:  "ignore paths",
+			a:     `{"id": 1, "updated_at": "2020-01-01", "items": [{"id": 2, "etag": "x"}]}`,
+			b:     `{"id": 1, "updated_at": "2021-06-30", "items": [{"id": 2, "etag": "y"}]}`,
+			opts:  EqualOptions{IgnorePaths: []string{"/updated_at", "/items/0/etag"}},
+			equal: true,
+		},
+		{
+			name:  "ignore missing path",
+			a:     `{"id": 1, "updated_at": "2020-01-01"}`,
+			b:     `{"id": 1}`,
+			opts:  EqualOptions{IgnorePaths: []string{"/updated_at"}},
+			equal: true,
+		},
+		{name: "ignore other paths", a: `{"id": 1}`, b: `{"id": 2}`, opts: EqualOptions{IgnorePaths: []string{"/updated_at"}}},
+		{name: "types", a: `[null, true, "1"]`, b: `[false, 1, 1]`, opts: defaultEqualOptions},
+		{name: "null", a: `null`, b: `null`, equal: true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			a, b := unmarshalNumbers(t, test.a), unmarshalNumbers(t, test.b)
+
+			require.Equal(t, test.equal, EqualWithOptions(a, b, test.opts))
+			require.Equal(t, test.equal, EqualWithOptions(b, a, test.opts))
+
+			if test.equal {
+				require.Equal(t, a.HashWithOptions(test.opts), b.HashWithOptions(test.opts))
+			}
+		})
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Parallel()
+
+		a := unmarshalNumbers(t, `{"count": 1, "name": "x"}`)
+		b := NewFromValue(map[string]interface{}{"name": "x", "count": 1.0})
+
+		require.True(t, Equal(a, b))
+		require.Equal(t, a.Hash(), b.Hash())
+
+		require.True(t, Equal(a.Get("name"), b.Get("name")))
+		require.False(t, Equal(a.Get("name"), b.Get("count")))
+	})
+
+	t.Run("UseNumber and float64", func(t *testing.T) {
+		t.Parallel()
+
+		const data = `{"price": 0.1, "items": [{"p": 19.99}, {"p": 1e2}], "id": 9007199254740993}`
+
+		a := unmarshalNumbers(t, data)
+		b := new(JSONNode)
+		require.NoError(t, json.Unmarshal([]byte(data), b))
+
+		// The float64 9007199254740992 is the closest to the id, but not the same number
+		require.False(t, Equal(a, b))
+		require.NoError(t, b.Set("id", json.Number("9007199254740993")))
+		require.True(t, Equal(a, b))
+		require.Equal(t, a.Hash(), b.Hash())
+
+		patch, err := Diff(a, b)
+		require.NoError(t, err)
+		require.Empty(t, patch)
+
+		require.NoError(t, a.ApplyPatch([]byte(`[
+			{"op": "test", "path": "/price", "value": 0.1},
+			{"op": "test", "path": "/items/1/p", "value": 100}
+		]`)))
+		require.NoError(t, a.ApplyPatch(Patch{{Op: "test", Path: "/items/0/p", Value: 19.99}}))
+
+		require.False(t, Equal(a.Get("price"), NewFromValue(json.Number("0.1000000000000000001"))))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+
+		a := unmarshalNumbers(t, `{"a": null}`)
+
+		require.True(t, Equal(a.Get("b"), nil))
+		require.False(t, Equal(a.Get("a"), a.Get("b")))
+		require.False(t, Equal(nil, a))
+		require.NotEqual(t, a.Get("a").Hash(), a.Get("b").Hash())
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		t.Parallel()
+
+		seen := map[uint64]string{}
+		for _, data := range []string{
+			`null`, `true`, `false`, `0`, `1`, `"1"`, `""`, `[]`, `{}`, `[[]]`, `[{}]`,
+			`[1, 2]`, `[2, 1]`, `{"a": 1}`, `{"a": "1"}`, `{"b": 1}`, `{"a": {"b": 1}}`, `{"a": 1, "b": 2}`,
+		} {
+			h := unmarshalNumbers(t, data).Hash()
+			require.NotContains(t, seen, h, "%s has the same hash as %s", data, seen[h])
+			seen[h] = data
+		}
+
+		// The hash is stable, so it can be stored
+		require.Equal(t, uint64(0xaf63e34c8601f871), unmarshalNumbers(t, `null`).Hash())
+
+		// The order of members and how numbers are written don't change the hash
+		require.Equal(t,
+			unmarshalNumbers(t, `{"a": 1, "b": [1.5, 2]}`).Hash(),
+			unmarshalNumbers(t, `{"b": [15e-1, 2.0], "a": 1.0}`).Hash(),
+		)
+
+		opts := EqualOptions{}
+		require.NotEqual(t,
+			unmarshalNumbers(t, `{"a": 1, "b": 2}`).HashWithOptions(opts),
+			unmarshalNumbers(t, `{"b": 2, "a": 1}`).HashWithOptions(opts),
+		)
+	})
+}
+
+func ExampleEqualWithOptions() {
+	a, err := Unmarshal([]byte(`{"id": 7, "tags": ["a", "b"], "updated_at": "2020-01-01"}`), DecoderOptions{})
+	if err != nil {
+		panic(err)
+	}
+
+	b := new(JSONNode)
+	err = json.Unmarshal([]byte(`{"tags": ["b", "a"], "id": 7.0, "updated_at": "2021-06-30"}`), b)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(Equal(a, b))
+	fmt.Println(EqualWithOptions(a, b, EqualOptions{
+		NumbersByValue:    true,
+		IgnoreKeyOrder:    true,
+		ArraysAsMultisets: true,
+		IgnorePaths:       []string{"/updated_at"},
+	}))
+
+	// Output:
+	// false
+	// true
+}
@@ -0,0 +1,287 @@
+package jsonnode
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// field is a struct field that is marshalled to (or unmarshalled from) a JSON object member,
+// following the same rules as encoding/json.
+type field struct {
+	name      string
+	tagged    bool  // The name came from a json tag
+	index     []int // For reflect.Value.FieldByIndex, including any embedded structs
+	typ       reflect.Type
+	omitEmpty bool
+	quoted    bool // The ",string" tag option applies
+}
+
+// structFields is the fields of a struct type.
+type structFields struct {
+	list   []field
+	byName map[string]int // Index into list
+}
+
+// fieldCache caches structFields by reflect.Type.
+var fieldCache sync.Map
+
+// cachedTypeFields gets the fields of struct type t, caching the result.
+func cachedTypeFields(t reflect.Type) *structFields {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.(*structFields)
+	}
+
+	fields, _ := fieldCache.LoadOrStore(t, typeFields(t))
+
+	return fields.(*structFields)
+}
+
+// find finds the field for a JSON object member. Like encoding/json, an exact match is preferred,
+// but the match is otherwise case-insensitive.
+func (fields *structFields) find(name string) *field {
+	if i, ok := fields.byName[name]; ok {
+		return &fields.list[i]
+	}
+
+	for i := range fields.list {
+		if strings.EqualFold(fields.list[i].name, name) {
+			return &fields.list[i]
+		}
+	}
+
+	return nil
+}
+
+// typeFields works out which fields of struct type t are marshalled to JSON, following the rules of
+// encoding/json. Fields of embedded structs are promoted, with Go's visibility rules deciding between
+// fields with the same name (except that a json tag breaks ties between fields at the same depth).
+func typeFields(t reflect.Type) *structFields {
+	var current []field
+	next := []field{{typ: t}}
+
+	// The number of times each type has been seen at the current and next depths
+	var count map[reflect.Type]int
+	nextCount := map[reflect.Type]int{}
+
+	visited := map[reflect.Type]bool{}
+
+	var fields []field
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+
+		for _, f := range current {
+			if visited[f.typ] {
+				continue
+			}
+
+			visited[f.typ] = true
+
+			for i := 0; i < f.typ.NumField(); i++ {
+				sf := f.typ.Field(i)
+				exported := sf.PkgPath == ""
+
+				if sf.Anonymous {
+					ft := sf.Type
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+
+					// The exported fields of unexported embedded structs are still promoted
+					if !exported && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !exported {
+					continue
+				}
+
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+
+				name, opts := parseTag(tag)
+				if !isValidTag(name) {
+					name = ""
+				}
+
+				index := make([]int, len(f.index)+1)
+				copy(index, f.index)
+				index[len(f.index)] = i
+
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+
+				quoted := false
+				if tagOption(opts, "string") {
+					switch ft.Kind() {
+					case reflect.Bool,
+						reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+						reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+						reflect.Float32, reflect.Float64,
+						reflect.String:
+						quoted = true
+					}
+				}
+
+				if name != "" || !sf.Anonymous || ft.Kind() != reflect.Struct {
+					tagged := name != ""
+					if name == "" {
+						name = sf.Name
+					}
+
+					fields = append(fields, field{
+						name:      name,
+						tagged:    tagged,
+						index:     index,
+						typ:       ft,
+						omitEmpty: tagOption(opts, "omitempty"),
+						quoted:    quoted,
+					})
+
+					if count[f.typ] > 1 {
+						// The same struct was embedded more than once at this depth, so its fields
+						// conflict with each other. Adding a duplicate makes sure they are dropped.
+						fields = append(fields, fields[len(fields)-1])
+					}
+
+					continue
+				}
+
+				// An untagged embedded struct; look at its fields at the next depth
+				nextCount[ft]++
+				if nextCount[ft] == 1 {
+					next = append(next, field{name: ft.Name(), index: index, typ: ft})
+				}
+			}
+		}
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		x := fields
+		if x[i].name != x[j].name {
+			return x[i].name < x[j].name
+		}
+
+		if len(x[i].index) != len(x[j].index) {
+			return len(x[i].index) < len(x[j].index)
+		}
+
+		if x[i].tagged != x[j].tagged {
+			return x[i].tagged
+		}
+
+		return indexLess(x[i].index, x[j].index)
+	})
+
+	// Pick the dominant field for each name, dropping names that are ambiguous
+	out := fields[:0]
+	for advance, i := 0, 0; i < len(fields); i += advance {
+		name := fields[i].name
+
+		advance = 1
+		for advance < len(fields)-i && fields[i+advance].name == name {
+			advance++
+		}
+
+		if advance == 1 {
+			out = append(out, fields[i])
+			continue
+		}
+
+		if dominant, ok := dominantField(fields[i : i+advance]); ok {
+			out = append(out, dominant)
+		}
+	}
+
+	fields = out
+	sort.Slice(fields, func(i, j int) bool {
+		return indexLess(fields[i].index, fields[j].index)
+	})
+
+	sf := &structFields{
+		list:   fields,
+		byName: make(map[string]int, len(fields)),
+	}
+
+	for i := range fields {
+		sf.byName[fields[i].name] = i
+	}
+
+	return sf
+}
+
+// dominantField picks the field that wins out of fields with the same name, which are sorted by
+// depth and then by whether they are tagged. false is returned if there is no single winner.
+func dominantField(fields []field) (field, bool) {
+	if len(fields) > 1 && len(fields[0].index) == len(fields[1].index) && fields[0].tagged == fields[1].tagged {
+		return field{}, false
+	}
+
+	return fields[0], true
+}
+
+func indexLess(a, b []int) bool {
+	for i := range a {
+		if i >= len(b) {
+			return false
+		}
+
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+
+	return len(a) < len(b)
+}
+
+// parseTag splits a json struct tag into the name and the options.
+func parseTag(tag string) (string, string) {
+	if i := strings.Index(tag, ","); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+
+	return tag, ""
+}
+
+// tagOption reports whether a comma-separated list of json tag options contains option.
+func tagOption(opts, option string) bool {
+	for opts != "" {
+		var opt string
+
+		opt, opts = opts, ""
+		if i := strings.Index(opt, ","); i >= 0 {
+			opt, opts = opt[:i], opt[i+1:]
+		}
+
+		if opt == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isValidTag reports whether a json tag name can be used, using the same rules as encoding/json.
+func isValidTag(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, c := range name {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c):
+			// Allowed punctuation
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			return false
+		}
+	}
+
+	return true
+}
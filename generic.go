@@ -0,0 +1,77 @@
+//go:build go1.18
+// +build go1.18
+
+package jsonnode
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// As converts the value of jn into a T, which can be any type encoding/json can unmarshal into
+// (a struct, a slice of structs, a map[string]T, a primitive, and so on). See Decode.
+// A *PathError is returned if jn doesn't exist, or its value can't be converted into a T.
+func As[T any](jn *JSONNode) (T, error) {
+	var result T
+
+	if !jn.Exists() {
+		return result, &PathError{Path: jn.JSONPointer(), Actual: KindMissing}
+	}
+
+	var err error
+
+	// Common types don't need to go through encoding/json
+	switch p := interface{}(&result).(type) {
+	case *string:
+		*p, err = jn.String()
+
+	case *bool:
+		*p, err = jn.Bool()
+
+	case *float64:
+		*p, err = jn.Float64()
+
+	case *int64:
+		*p, err = jn.Int64()
+
+	case *uint64:
+		*p, err = jn.Uint64()
+
+	case *int:
+		var i int64
+
+		i, err = jn.Int64()
+		if err == nil && strconv.IntSize == 32 && int64(int32(i)) != i {
+			err = jn.numberError(ErrOverflow)
+		}
+
+		*p = int(i)
+
+	case *json.Number:
+		*p, err = jn.Number()
+
+	default:
+		err = jn.Decode(&result)
+		if err != nil {
+			err = &PathError{Path: jn.JSONPointer(), Actual: jn.Kind(), Err: err}
+		}
+	}
+
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return result, nil
+}
+
+// GetAs follows path from jn (see Lookup), then converts the value found there into a T (see As).
+func GetAs[T any](jn *JSONNode, path ...interface{}) (T, error) {
+	node, err := jn.Lookup(path...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return As[T](node)
+}
@@ -0,0 +1,96 @@
+//go:build go1.18
+// +build go1.18
+
+package jsonnode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fruit struct {
+	Type  string `json:"type"`
+	Count int    `json:"count,omitempty"`
+}
+
+func TestAs(t *testing.T) {
+	t.Parallel()
+
+	jn := unmarshalPlatter(t)
+
+	t.Run("structs", func(t *testing.T) {
+		t.Parallel()
+
+		fruits, err := GetAs[[]fruit](jn, "with", "fruit")
+		require.NoError(t, err)
+		require.Equal(t, []fruit{{Type: "grapes", Count: 8}, {Type: "strawberries", Count: 3}}, fruits)
+
+		f, err := As[fruit](jn.Get("with").Get("fruit").Index(1))
+		require.NoError(t, err)
+		require.Equal(t, fruit{Type: "strawberries", Count: 3}, f)
+
+		m, err := GetAs[map[string]string](jn, "with", "fruit", 0)
+		require.Error(t, err)
+		require.Nil(t, m)
+
+		var pathErr *PathError
+		require.True(t, errors.As(err, &pathErr))
+		require.Equal(t, "/with/fruit/0", pathErr.Path)
+	})
+
+	t.Run("primitives", func(t *testing.T) {
+		t.Parallel()
+
+		s, err := GetAs[string](jn, "platter")
+		require.NoError(t, err)
+		require.Equal(t, "slate", s)
+
+		i, err := GetAs[int](jn, "with", "fruit", 0, "count")
+		require.NoError(t, err)
+		require.Equal(t, 8, i)
+
+		f, err := GetAs[float64](jn, "with", "fruit", 0, "count")
+		require.NoError(t, err)
+		require.Equal(t, float64(8), f)
+
+		cheeses, err := GetAs[[]string](jn, "cheeses")
+		require.NoError(t, err)
+		require.Equal(t, []string{"cheddar", "swiss", "manchego"}, cheeses)
+
+		_, err = GetAs[bool](jn, "platter")
+		require.EqualError(t, err, `jsonnode: "/platter": expected bool, found string`)
+
+		_, err = GetAs[string](jn, "with", "bread")
+		require.True(t, errors.Is(err, ErrNotFound))
+
+		_, err = As[string](nil)
+		require.True(t, errors.Is(err, ErrNotFound))
+	})
+}
+
+func ExampleGetAs() {
+	jn := new(JSONNode)
+	err := json.Unmarshal([]byte(platterJSON), jn)
+	if err != nil {
+		panic(err)
+	}
+
+	type fruit struct {
+		Type  string `json:"type"`
+		Count int    `json:"count"`
+	}
+
+	fruits, err := GetAs[[]fruit](jn, "with", "fruit")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%+v\n", fruits)
+
+	// Output:
+	// [{Type:grapes Count:8} {Type:strawberries Count:3}]
+}
@@ -0,0 +1,429 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+var _ json.Marshaler = (*Immutable)(nil)
+
+// Immutable is a JSON document (or a node in one) that can't be changed. It can be read from any
+// number of goroutines without locking.
+// Changes are made with With and Without, which return a new document. The new document shares
+// everything that wasn't changed with the old one, so only the values along the path are copied.
+type Immutable struct {
+	node *JSONNode
+}
+
+// NewImmutable creates an Immutable holding a copy of the document (or subtree) at jn.
+// If jn does not exist, the document is a JSON null.
+func NewImmutable(jn *JSONNode) *Immutable {
+	root := jn.Clone()
+	if root == nil {
+		root = NewFromValue(nil)
+	}
+
+	return &Immutable{node: root}
+}
+
+// newImmutableNode wraps jn, which must only refer to values that are never changed.
+func newImmutableNode(jn *JSONNode) *Immutable {
+	if jn == nil {
+		return nil
+	}
+
+	return &Immutable{node: jn}
+}
+
+// jn gets the node behind this one, which must not be changed.
+func (im *Immutable) jn() *JSONNode {
+	if im == nil {
+		return nil
+	}
+
+	return im.node
+}
+
+// Thaw makes a copy of this node and everything under it, as the root of a new document that can
+// be changed. nil is returned if this node does not exist.
+func (im *Immutable) Thaw() *JSONNode {
+	return im.jn().Clone()
+}
+
+// MarshalJSON marshals this node to JSON.
+func (im *Immutable) MarshalJSON() ([]byte, error) {
+	return im.jn().MarshalJSON()
+}
+
+// Get gets the specified member of this JSON object, or nil if there isn't one.
+func (im *Immutable) Get(fieldName string) *Immutable {
+	return newImmutableNode(im.jn().Get(fieldName))
+}
+
+// Index gets the element at index i of this JSON array, or nil if there isn't one.
+func (im *Immutable) Index(i int) *Immutable {
+	return newImmutableNode(im.jn().Index(i))
+}
+
+// Pointer gets the node referred to by the JSON pointer (RFC 6901), relative to this node.
+// If the pointer is invalid or the node doesn't exist, nil will be returned.
+func (im *Immutable) Pointer(ptr string) *Immutable {
+	return newImmutableNode(im.jn().Pointer(ptr))
+}
+
+// Lookup follows path from this node, returning the node found there (see JSONNode.Lookup).
+func (im *Immutable) Lookup(path ...interface{}) (*Immutable, error) {
+	node, err := im.jn().Lookup(path...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newImmutableNode(node), nil
+}
+
+// JSONPointer gets the JSON pointer (RFC 6901) to this node from the root of the document it is in.
+func (im *Immutable) JSONPointer() string {
+	return im.jn().JSONPointer()
+}
+
+// Keys gets the names of the members of this JSON object, in order.
+func (im *Immutable) Keys() []string {
+	return im.jn().Keys()
+}
+
+// Kind gets the kind of JSON value held by this node.
+func (im *Immutable) Kind() Kind {
+	return im.jn().Kind()
+}
+
+// Exists reports whether this node exists.
+func (im *Immutable) Exists() bool {
+	return im.jn().Exists()
+}
+
+// IsNull reports whether this node holds a JSON null.
+func (im *Immutable) IsNull() bool {
+	return im.jn().IsNull()
+}
+
+// IsObject reports whether this node holds a JSON object.
+func (im *Immutable) IsObject() bool {
+	return im.jn().IsObject()
+}
+
+// IsArray reports whether this node holds a JSON array.
+func (im *Immutable) IsArray() bool {
+	return im.jn().IsArray()
+}
+
+// Len gets the number of members of a JSON object, or elements of a JSON array.
+func (im *Immutable) Len() int {
+	return im.jn().Len()
+}
+
+// Value gets the raw value of this node (see JSONNode.Value).
+func (im *Immutable) Value() interface{} {
+	return im.jn().Value()
+}
+
+// OrderedValue gets the raw value of this node, with JSON objects as *Object (see
+// JSONNode.OrderedValue).
+// It is shared with other documents, so it must not be changed.
+func (im *Immutable) OrderedValue() interface{} {
+	return im.jn().OrderedValue()
+}
+
+// ValueAsString gets the value of this node as a string.
+func (im *Immutable) ValueAsString() (string, bool) {
+	return im.jn().ValueAsString()
+}
+
+// ValueAsBool gets the value of this node as a bool.
+func (im *Immutable) ValueAsBool() (bool, bool) {
+	return im.jn().ValueAsBool()
+}
+
+// ValueAsFloat64 gets the value of this node as a float64.
+func (im *Immutable) ValueAsFloat64() (float64, bool) {
+	return im.jn().ValueAsFloat64()
+}
+
+// ValueAsInt64 gets the value of this node as an int64 (see JSONNode.ValueAsInt64).
+func (im *Immutable) ValueAsInt64() (int64, error) {
+	return im.jn().ValueAsInt64()
+}
+
+// ValueAsUint64 gets the value of this node as a uint64 (see JSONNode.ValueAsUint64).
+func (im *Immutable) ValueAsUint64() (uint64, error) {
+	return im.jn().ValueAsUint64()
+}
+
+// ValueAsBigInt gets the value of this node as a *big.Int (see JSONNode.ValueAsBigInt).
+func (im *Immutable) ValueAsBigInt() (*big.Int, error) {
+	return im.jn().ValueAsBigInt()
+}
+
+// ValueAsBigFloat gets the value of this node as a *big.Float (see JSONNode.ValueAsBigFloat).
+func (im *Immutable) ValueAsBigFloat() (*big.Float, error) {
+	return im.jn().ValueAsBigFloat()
+}
+
+// ValueAsNumber gets the value of this node as a json.Number (see JSONNode.ValueAsNumber).
+func (im *Immutable) ValueAsNumber() (json.Number, error) {
+	return im.jn().ValueAsNumber()
+}
+
+// ValueAsSlice gets the elements of this JSON array.
+func (im *Immutable) ValueAsSlice() ([]*Immutable, bool) {
+	nodes, ok := im.jn().ValueAsSlice()
+	if !ok {
+		return nil, false
+	}
+
+	elements := make([]*Immutable, len(nodes))
+	for i, node := range nodes {
+		elements[i] = newImmutableNode(node)
+	}
+
+	return elements, true
+}
+
+// With returns a new document that is a copy of this node with the value at path set to a copy
+// of value. This node is not changed.
+// Each element of path is either a string (the name of an object member) or an int (an array
+// index). Everything up to the last element must already exist. If the last element names a JSON
+// object member, it is added or replaced. If it is an array index, that element is replaced, or
+// value is appended if the index is the length of the array. An empty path replaces the whole value.
+// value can be anything FromValue accepts. An *Immutable is used as-is, without copying.
+func (im *Immutable) With(path Path, value interface{}) (*Immutable, error) {
+	var val interface{}
+	var err error
+
+	if other, ok := value.(*Immutable); ok {
+		var exists bool
+		val, exists = other.jn().lookup()
+		if !exists {
+			return nil, &PathError{Path: other.JSONPointer(), Actual: KindMissing}
+		}
+	} else {
+		val, err = toValue(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return im.update(path, func(parent interface{}, last interface{}, pointer string) (interface{}, error) {
+		switch parent := parent.(type) {
+		case *Object:
+			key, ok := last.(string)
+			if !ok {
+				return nil, &PathError{Path: pointer, Expected: KindArray, Actual: KindObject}
+			}
+
+			obj := copyObject(parent)
+			obj.set(key, val)
+
+			return obj, nil
+
+		case []interface{}:
+			i, ok := last.(int)
+			if !ok {
+				return nil, &PathError{Path: pointer, Expected: KindObject, Actual: KindArray}
+			}
+
+			if i < 0 || i > len(parent) {
+				return nil, &PathError{Path: pointer + "/" + strconv.Itoa(i), Err: ErrIndexOutOfRange}
+			}
+
+			arr := make([]interface{}, len(parent), len(parent)+1)
+			copy(arr, parent)
+
+			if i == len(parent) {
+				return append(arr, val), nil
+			}
+
+			arr[i] = val
+
+			return arr, nil
+		}
+
+		return nil, &PathError{Path: pointer, Expected: pathKind(last), Actual: kindOf(parent)}
+	}, val)
+}
+
+// Without returns a new document that is a copy of this node with the value at path removed.
+// This node is not changed. Removing an element from a JSON array shifts any following elements
+// down by one. It is an error for the value to not exist, or for path to be empty.
+func (im *Immutable) Without(path Path) (*Immutable, error) {
+	if len(path) == 0 {
+		return nil, errors.New("jsonnode: cannot remove the whole document")
+	}
+
+	return im.update(path, func(parent interface{}, last interface{}, pointer string) (interface{}, error) {
+		switch parent := parent.(type) {
+		case *Object:
+			key, ok := last.(string)
+			if !ok {
+				return nil, &PathError{Path: pointer, Expected: KindArray, Actual: KindObject}
+			}
+
+			if _, ok := parent.Get(key); !ok {
+				return nil, &PathError{Path: pointer + "/" + escapePointerToken(key), Actual: KindMissing}
+			}
+
+			obj := copyObject(parent)
+			obj.Delete(key)
+
+			return obj, nil
+
+		case []interface{}:
+			i, ok := last.(int)
+			if !ok {
+				return nil, &PathError{Path: pointer, Expected: KindObject, Actual: KindArray}
+			}
+
+			if i < 0 || i >= len(parent) {
+				return nil, &PathError{Path: pointer + "/" + strconv.Itoa(i), Actual: KindMissing}
+			}
+
+			arr := make([]interface{}, 0, len(parent)-1)
+			arr = append(arr, parent[:i]...)
+			arr = append(arr, parent[i+1:]...)
+
+			return arr, nil
+		}
+
+		return nil, &PathError{Path: pointer, Expected: pathKind(last), Actual: kindOf(parent)}
+	}, nil)
+}
+
+// update copies the values along path, using change to make the new value of the parent of the
+// last element of path. If path is empty, root is the new value.
+func (im *Immutable) update(
+	path Path,
+	change func(parent interface{}, last interface{}, pointer string) (interface{}, error),
+	root interface{},
+) (*Immutable, error) {
+	value, ok := im.jn().lookup()
+	if !ok {
+		return nil, &PathError{Path: im.JSONPointer(), Actual: KindMissing}
+	}
+
+	for _, segment := range path {
+		switch segment.(type) {
+		case string, int:
+		default:
+			return nil, fmt.Errorf("jsonnode: path elements must be a string or int, not %T", segment)
+		}
+	}
+
+	if len(path) == 0 {
+		return newImmutableValue(root), nil
+	}
+
+	updated, err := updateValue(value, path, change, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return newImmutableValue(updated), nil
+}
+
+// updateValue implements update for value, which is at pointer (relative to where update started).
+func updateValue(
+	value interface{},
+	path Path,
+	change func(parent interface{}, last interface{}, pointer string) (interface{}, error),
+	pointer string,
+) (interface{}, error) {
+	if len(path) == 1 {
+		return change(value, path[0], pointer)
+	}
+
+	switch segment := path[0].(type) {
+	case string:
+		obj, ok := value.(*Object)
+		if !ok {
+			return nil, &PathError{Path: pointer, Expected: KindObject, Actual: kindOf(value)}
+		}
+
+		childPointer := pointer + "/" + escapePointerToken(segment)
+
+		child, ok := obj.Get(segment)
+		if !ok {
+			return nil, &PathError{Path: childPointer, Actual: KindMissing}
+		}
+
+		child, err := updateValue(child, path[1:], change, childPointer)
+		if err != nil {
+			return nil, err
+		}
+
+		updated := copyObject(obj)
+		updated.set(segment, child)
+
+		return updated, nil
+
+	default:
+		i := segment.(int)
+
+		arr, ok := value.([]interface{})
+		if !ok {
+			return nil, &PathError{Path: pointer, Expected: KindArray, Actual: kindOf(value)}
+		}
+
+		childPointer := pointer + "/" + strconv.Itoa(i)
+
+		if i < 0 || i >= len(arr) {
+			return nil, &PathError{Path: childPointer, Actual: KindMissing}
+		}
+
+		child, err := updateValue(arr[i], path[1:], change, childPointer)
+		if err != nil {
+			return nil, err
+		}
+
+		updated := make([]interface{}, len(arr))
+		copy(updated, arr)
+		updated[i] = child
+
+		return updated, nil
+	}
+}
+
+// newImmutableValue creates a new document with value as its root.
+func newImmutableValue(value interface{}) *Immutable {
+	jn := new(JSONNode)
+	jn.init()
+	jn.data = value
+
+	return &Immutable{node: jn}
+}
+
+// copyObject makes a shallow copy of obj. The values of its members are shared.
+func copyObject(obj *Object) *Object {
+	c := &Object{
+		keys:   make([]string, len(obj.keys)),
+		values: make(map[string]interface{}, len(obj.keys)),
+	}
+
+	copy(c.keys, obj.keys)
+
+	for key, val := range obj.values {
+		c.values[key] = val
+	}
+
+	return c
+}
+
+// pathKind gets the kind of value a path element can be followed into.
+func pathKind(segment interface{}) Kind {
+	if _, ok := segment.(int); ok {
+		return KindArray
+	}
+
+	return KindObject
+}
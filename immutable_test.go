@@ -0,0 +1,223 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmutable(t *testing.T) {
+	t.Parallel()
+
+	freeze := func(t *testing.T) *Immutable {
+		return NewImmutable(unmarshalPlatter(t))
+	}
+
+	t.Run("read", func(t *testing.T) {
+		t.Parallel()
+
+		im := freeze(t)
+		require.Equal(t, []string{"platter", "cheeses", "with"}, im.Keys())
+		require.Equal(t, "slate", im.Get("platter").Value())
+
+		s, ok := im.Pointer("/cheeses/2").ValueAsString()
+		require.True(t, ok)
+		require.Equal(t, "manchego", s)
+
+		count, err := im.Pointer("/with/fruit/1/count").ValueAsInt64()
+		require.NoError(t, err)
+		require.Equal(t, int64(3), count)
+
+		fruit, ok := im.Get("with").Get("fruit").ValueAsSlice()
+		require.True(t, ok)
+		require.Len(t, fruit, 2)
+		require.Equal(t, "/with/fruit/1", fruit[1].JSONPointer())
+
+		node, err := im.Lookup("with", "fruit", 0, "type")
+		require.NoError(t, err)
+		require.Equal(t, KindString, node.Kind())
+
+		_, err = im.Lookup("with", "fruit", 5)
+		require.True(t, errors.Is(err, ErrNotFound), "%v", err)
+
+		require.Nil(t, im.Get("nope"))
+		require.Nil(t, im.Index(0))
+		require.False(t, im.Get("nope").Exists())
+		require.Equal(t, KindMissing, im.Get("nope").Kind())
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		t.Parallel()
+
+		jn := NewFromValue(map[string]interface{}{"a": []interface{}{1.0}})
+		im := NewImmutable(jn)
+		require.NoError(t, jn.Get("a").Append(2.0))
+		require.Equal(t, `{"a":[1]}`, marshal(t, im))
+
+		thawed := im.Thaw()
+		require.NoError(t, thawed.Get("a").Append(3.0))
+		require.Equal(t, `{"a":[1]}`, marshal(t, im))
+
+		require.NoError(t, jn.Set("b", im))
+		require.NoError(t, jn.Pointer("/b/a").Append(4.0))
+		require.Equal(t, `{"a":[1]}`, marshal(t, im))
+
+		require.Equal(t, "null", marshal(t, NewImmutable(nil)))
+	})
+
+	t.Run("with", func(t *testing.T) {
+		t.Parallel()
+
+		im := freeze(t)
+		before := marshal(t, im)
+
+		updated, err := im.With(Path{"with", "fruit", 0, "count"}, 9)
+		require.NoError(t, err)
+		require.Equal(t, before, marshal(t, im))
+		require.Equal(t, 9.0, updated.Pointer("/with/fruit/0/count").Value())
+
+		// Everything off the path is shared
+		require.True(t, &im.Get("cheeses").OrderedValue().([]interface{})[0] == &updated.Get("cheeses").OrderedValue().([]interface{})[0])
+		require.True(t, im.Pointer("/with/fruit/1").OrderedValue() == updated.Pointer("/with/fruit/1").OrderedValue())
+		require.False(t, im.Pointer("/with/fruit/0").OrderedValue() == updated.Pointer("/with/fruit/0").OrderedValue())
+
+		updated, err = updated.With(Path{"cheeses", 3}, "brie")
+		require.NoError(t, err)
+		require.Equal(t, 4, updated.Get("cheeses").Len())
+		require.Equal(t, 3, im.Get("cheeses").Len())
+
+		updated, err = updated.With(Path{"with", "bread"}, map[string]interface{}{"type": "baguette"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"fruit", "meat", "bread"}, updated.Get("with").Keys())
+
+		updated, err = updated.With(Path{"platter"}, im.Get("with").Get("meat"))
+		require.NoError(t, err)
+		require.Equal(t, "prosciutto", updated.Get("platter").Value())
+
+		replaced, err := updated.With(nil, true)
+		require.NoError(t, err)
+		require.Equal(t, true, replaced.Value())
+
+		sub, err := im.Get("with").With(Path{"meat"}, "salami")
+		require.NoError(t, err)
+		require.Equal(t, `{"fruit":[{"type":"grapes","count":8},{"type":"strawberries","count":3}],"meat":"salami"}`, marshal(t, sub))
+		require.Equal(t, "", sub.JSONPointer())
+	})
+
+	t.Run("without", func(t *testing.T) {
+		t.Parallel()
+
+		im := freeze(t)
+
+		updated, err := im.Without(Path{"cheeses", 0})
+		require.NoError(t, err)
+		require.Equal(t, []interface{}{"swiss", "manchego"}, updated.Get("cheeses").Value())
+
+		updated, err = updated.Without(Path{"with", "meat"})
+		require.NoError(t, err)
+		require.Nil(t, updated.Get("with").Get("meat"))
+		require.Equal(t, "prosciutto", im.Get("with").Get("meat").Value())
+
+		require.True(t, im.Get("with").Get("fruit").OrderedValue().([]interface{})[0] == updated.Get("with").Get("fruit").OrderedValue().([]interface{})[0])
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		im := freeze(t)
+
+		tests := []struct {
+			name string
+			fn   func() (*Immutable, error)
+			err  *PathError
+		}{
+			{
+				name: "missing parent",
+				fn:   func() (*Immutable, error) { return im.With(Path{"with", "bread", "type"}, "rye") },
+				err:  &PathError{Path: "/with/bread", Actual: KindMissing},
+			},
+			{
+				name: "index out of range",
+				fn:   func() (*Immutable, error) { return im.With(Path{"cheeses", 4}, "brie") },
+				err:  &PathError{Path: "/cheeses/4", Err: ErrIndexOutOfRange},
+			},
+			{
+				name: "not an array",
+				fn:   func() (*Immutable, error) { return im.With(Path{"with", 0}, "brie") },
+				err:  &PathError{Path: "/with", Expected: KindArray, Actual: KindObject},
+			},
+			{
+				name: "not an object",
+				fn:   func() (*Immutable, error) { return im.With(Path{"platter", "color"}, "grey") },
+				err:  &PathError{Path: "/platter", Expected: KindObject, Actual: KindString},
+			},
+			{
+				name: "remove missing",
+				fn:   func() (*Immutable, error) { return im.Without(Path{"with", "bread"}) },
+				err:  &PathError{Path: "/with/bread", Actual: KindMissing},
+			},
+			{
+				name: "remove out of range",
+				fn:   func() (*Immutable, error) { return im.Without(Path{"cheeses", 3}) },
+				err:  &PathError{Path: "/cheeses/3", Actual: KindMissing},
+			},
+			{
+				name: "missing node",
+				fn:   func() (*Immutable, error) { return im.Get("nope").With(Path{"a"}, 1) },
+				err:  &PathError{Actual: KindMissing},
+			},
+		}
+
+		for _, test := range tests {
+			updated, err := test.fn()
+			require.Nil(t, updated, test.name)
+			require.Equal(t, test.err, err, test.name)
+		}
+
+		_, err := im.With(Path{1.5}, 1)
+		require.EqualError(t, err, "jsonnode: path elements must be a string or int, not float64")
+
+		_, err = im.Without(nil)
+		require.Error(t, err)
+
+		_, err = im.With(Path{"a"}, func() {})
+		require.Error(t, err)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		im := freeze(t)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				mine, err := im.With(Path{"with", "fruit", i % 2, "count"}, i)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				if _, err := json.Marshal(im); err != nil {
+					t.Error(err)
+					return
+				}
+
+				if got := mine.Pointer(fmt.Sprintf("/with/fruit/%d/count", i%2)).Value(); got != float64(i) {
+					t.Errorf("expected %d, got %v", i, got)
+				}
+			}(i)
+		}
+
+		wg.Wait()
+
+		require.Equal(t, 8.0, im.Pointer("/with/fruit/0/count").Value())
+	})
+}
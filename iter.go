@@ -0,0 +1,66 @@
+//go:build go1.23
+// +build go1.23
+
+package jsonnode
+
+import (
+	"errors"
+	"iter"
+)
+
+// Fields iterates over the members of this JSON object, in order.
+// Nothing is iterated over if this node is not a JSON object.
+func (jn *JSONNode) Fields() iter.Seq2[string, *JSONNode] {
+	return func(yield func(string, *JSONNode) bool) {
+		obj, ok := jn.shallowValue().(*Object)
+		if !ok {
+			return
+		}
+
+		for _, key := range obj.Keys() {
+			child := jn.Get(key)
+			if child == nil {
+				// Removed while iterating
+				continue
+			}
+
+			if !yield(key, child) {
+				return
+			}
+		}
+	}
+}
+
+// Elements iterates over the elements of this JSON array, in order.
+// Nothing is iterated over if this node is not a JSON array.
+func (jn *JSONNode) Elements() iter.Seq2[int, *JSONNode] {
+	return func(yield func(int, *JSONNode) bool) {
+		for i := 0; ; i++ {
+			// Go by the array as it is now, in case it was changed while iterating
+			child := jn.Index(i)
+			if child == nil {
+				return
+			}
+
+			if !yield(i, child) {
+				return
+			}
+		}
+	}
+}
+
+// errStopIteration stops Walk when the loop over All ends early.
+var errStopIteration = errors.New("jsonnode: stop iteration")
+
+// All iterates over this node and each of its descendants, in the same order as Walk.
+func (jn *JSONNode) All() iter.Seq2[Path, *JSONNode] {
+	return func(yield func(Path, *JSONNode) bool) {
+		_ = jn.Walk(func(path Path, n *JSONNode) error {
+			if !yield(path, n) {
+				return errStopIteration
+			}
+
+			return nil
+		})
+	}
+}
@@ -0,0 +1,114 @@
+//go:build go1.23
+// +build go1.23
+
+package jsonnode
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONNodeIterators(t *testing.T) {
+	t.Parallel()
+
+	jn := unmarshalPlatter(t)
+
+	t.Run("fields", func(t *testing.T) {
+		t.Parallel()
+
+		var keys []string
+		for key, n := range jn.Fields() {
+			require.Equal(t, "/"+key, n.JSONPointer())
+			keys = append(keys, key)
+		}
+
+		require.Equal(t, []string{"platter", "cheeses", "with"}, keys)
+
+		keys = nil
+		for key := range jn.Fields() {
+			keys = append(keys, key)
+			break
+		}
+
+		require.Equal(t, []string{"platter"}, keys)
+
+		for range jn.Get("cheeses").Fields() {
+			t.Fatal("not an object")
+		}
+	})
+
+	t.Run("elements", func(t *testing.T) {
+		t.Parallel()
+
+		var cheeses []string
+		for i, n := range jn.Get("cheeses").Elements() {
+			s, ok := n.ValueAsString()
+			require.True(t, ok)
+			require.Len(t, cheeses, i)
+
+			cheeses = append(cheeses, s)
+		}
+
+		require.Equal(t, []string{"cheddar", "swiss", "manchego"}, cheeses)
+
+		for range jn.Elements() {
+			t.Fatal("not an array")
+		}
+
+		var nilNode *JSONNode
+		for range nilNode.Elements() {
+			t.Fatal("nil node")
+		}
+	})
+
+	t.Run("all", func(t *testing.T) {
+		t.Parallel()
+
+		var paths []string
+		for path, n := range jn.Get("with").Get("fruit").All() {
+			require.Equal(t, "/with/fruit"+path.String(), n.JSONPointer())
+			paths = append(paths, path.String())
+
+			if len(paths) == 4 {
+				break
+			}
+		}
+
+		require.Equal(t, []string{"", "/0", "/0/type", "/0/count"}, paths)
+	})
+
+	t.Run("modify", func(t *testing.T) {
+		t.Parallel()
+
+		doc := NewFromValue([]interface{}{1.0, 2.0, 3.0})
+		for i, n := range doc.Elements() {
+			if i == 0 {
+				require.NoError(t, doc.RemoveAt(2))
+			}
+
+			require.NoError(t, n.SetPointer("", float64(i*10)))
+		}
+
+		require.Equal(t, "[0,10]", marshal(t, doc))
+	})
+}
+
+func ExampleJSONNode_Fields() {
+	jn := new(JSONNode)
+	err := json.Unmarshal([]byte(`{"b": 1, "a": 2, "c": 3}`), jn)
+	if err != nil {
+		panic(err)
+	}
+
+	for key, n := range jn.Fields() {
+		fmt.Println(key, n.Value())
+	}
+
+	// Output:
+	// b 1
+	// a 2
+	// c 3
+}
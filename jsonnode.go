@@ -3,45 +3,102 @@
 // less code than using map[string]interface{}.
 package jsonnode
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+)
 
 var _ json.Marshaler = (*JSONNode)(nil)
 var _ json.Unmarshaler = (*JSONNode)(nil)
 
+var (
+	// ErrNilNode is returned when trying to modify a nil *JSONNode.
+	ErrNilNode = errors.New("jsonnode: nil node")
+
+	// ErrNotObject is returned when an operation requires a JSON object, but the node is something else.
+	ErrNotObject = errors.New("jsonnode: not a JSON object")
+
+	// ErrNotArray is returned when an operation requires a JSON array, but the node is something else.
+	ErrNotArray = errors.New("jsonnode: not a JSON array")
+
+	// ErrIndexOutOfRange is returned when an array index is outside of the bounds of a JSON array.
+	ErrIndexOutOfRange = errors.New("jsonnode: index out of range")
+)
+
 // JSONNode represents a JSON node to be marshalled (TODO), or that has been unmarshalled.
-// A JSONNode can be a whole JSON object that can be marshalled to JSON (TODO),
-// or that has been unmarshalled from JSON.
-// It can also represent a specific member (of any type) in a JSON object.
+// A JSONNode can be a whole JSON document (an object, an array, or even a single scalar value)
+// that can be marshalled to JSON (TODO), or that has been unmarshalled from JSON.
+// It can also represent a specific member (of any type) in a JSON object or array.
 type JSONNode struct {
 	parent    *JSONNode
 	fieldName string
-	data      map[string]interface{}
+	data      interface{}
 	index     int
+
+	// doc is shared by all of the nodes of a document.
+	doc *document
+
+	// parentCache is the value of parent, so it isn't looked up every time (see parentValue).
+	// It starts out pointing at firstCache, so creating a node only takes one allocation.
+	parentCache *parentCache
+	firstCache  parentCache
 }
 
 // New creates a new JSONNode, ready to put data into to marshal to JSON
 func New() *JSONNode {
 	jn := new(JSONNode)
 	jn.init()
-	jn.data = make(map[string]interface{})
+	jn.data = NewObject()
 
 	return jn
 }
 
-func newChild(parent *JSONNode, fieldName string) *JSONNode {
+// NewFromValue creates a new JSONNode with a copy of value as its root.
+// value is expected to be made up of the same types encoding/json unmarshals into an interface{}
+// (map[string]interface{}, []interface{}, string, float64, bool, and nil), or *Object.
+// Any map[string]interface{} is converted into an *Object, with its members sorted by name.
+// Use FromValue for any other Go value, such as a struct.
+func NewFromValue(value interface{}) *JSONNode {
 	jn := new(JSONNode)
 	jn.init()
-	jn.parent = parent
-	jn.fieldName = fieldName
+	jn.data = copyValue(value)
 
 	return jn
 }
 
+// newChild creates a node for a member of the JSON object held by parent.
+// parentValue is the value of parent as of generation gen.
+func newChild(parent *JSONNode, fieldName string, parentValue interface{}, gen generation) *JSONNode {
+	jn := &JSONNode{parent: parent, fieldName: fieldName, index: -1, doc: parent.doc}
+	jn.firstCache = parentCache{value: parentValue, generation: gen}
+	jn.parentCache = &jn.firstCache
+
+	return jn
+}
+
+// newElement creates a node for an element of the JSON array held by parent.
+// parentValue is the value of parent as of generation gen.
+func newElement(parent *JSONNode, index int, parentValue interface{}, gen generation) *JSONNode {
+	jn := &JSONNode{parent: parent, index: index, doc: parent.doc}
+	jn.firstCache = parentCache{value: parentValue, generation: gen}
+	jn.parentCache = &jn.firstCache
+
+	return jn
+}
+
+// init makes this the root node of a document. Any nodes that were already under it stay in the
+// same document, which sees the change.
 func (jn *JSONNode) init() {
 	jn.parent = nil
 	jn.fieldName = ""
 	jn.data = nil
 	jn.index = -1
+
+	if jn.doc == nil {
+		jn.doc = new(document)
+	} else {
+		jn.doc.invalidate()
+	}
 }
 
 // MarshalJSON marshals this instance to JSON
@@ -50,15 +107,26 @@ func (jn *JSONNode) MarshalJSON() ([]byte, error) {
 		return json.Marshal(nil)
 	}
 
-	return json.Marshal(jn.Value())
+	// Anything that hasn't been decoded is marshalled as it was
+	value, _ := jn.lookupRaw()
+
+	return json.Marshal(value)
 }
 
-// UnmarshalJSON unmarshals JSON into this instance of JSONNode
+// UnmarshalJSON unmarshals JSON into this instance of JSONNode.
+// The JSON can be any JSON value, not just an object.
+// The members of JSON objects are kept in the order they are in the JSON.
 func (jn *JSONNode) UnmarshalJSON(data []byte) error {
 	jn.init()
-	jn.data = make(map[string]interface{})
 
-	return json.Unmarshal(data, &jn.data)
+	value, err := decode(data, DecoderOptions{})
+	if err != nil {
+		return err
+	}
+
+	jn.data = value
+
+	return nil
 }
 
 // Get gets specified child field of this JSONNode.
@@ -69,10 +137,13 @@ func (jn *JSONNode) Get(fieldName string) *JSONNode {
 		return nil
 	}
 
-	switch t := jn.Value().(type) {
-	case map[string]interface{}:
+	gen := jn.doc.generation()
+	value := jn.shallowValue()
+
+	switch t := value.(type) {
+	case *Object:
 		// This node can have children
-		if _, ok := t[fieldName]; !ok {
+		if _, ok := t.Get(fieldName); !ok {
 			// This node does not have this child
 			return nil
 		}
@@ -82,74 +153,250 @@ func (jn *JSONNode) Get(fieldName string) *JSONNode {
 		return nil
 	}
 
-	child := newChild(jn, fieldName)
+	child := newChild(jn, fieldName, value, gen)
 
 	return child
 }
 
-// Value gets the raw value of this node
-func (jn *JSONNode) Value() interface{} {
+// Keys gets the names of the fields of this JSON object, in the order they are in the object.
+// nil will be returned if this node is not a JSON object.
+func (jn *JSONNode) Keys() []string {
+	obj, ok := jn.shallowValue().(*Object)
+	if !ok {
+		return nil
+	}
+
+	return obj.Keys()
+}
+
+// Index gets the specified element of this JSON array.
+// If this node is a JSON array and i is within its bounds, a *JSONNode will be returned.
+// Otherwise, nil will be returned (including if this *JSONNode instance is nil).
+func (jn *JSONNode) Index(i int) *JSONNode {
 	if jn == nil {
 		return nil
 	}
 
-	if jn.data == nil && jn.parent != nil {
+	gen := jn.doc.generation()
+
+	value := jn.shallowValue()
+
+	valSlice, ok := value.([]interface{})
+	if !ok || i < 0 || i >= len(valSlice) {
+		return nil
+	}
+
+	return newElement(jn, i, value, gen)
+}
+
+// Value gets the raw value of this node, the same as what encoding/json would unmarshal into an
+// interface{}.
+// JSON objects and arrays are copied, so changing them does not change the document. Use
+// OrderedValue to keep the order of the members of JSON objects.
+// If the document was decoded lazily (see DecoderOptions.Lazy), everything in the value is decoded.
+func (jn *JSONNode) Value() interface{} {
+	return mapValue(jn.OrderedValue())
+}
+
+// OrderedValue gets the raw value of this node, with JSON objects as *Object so the order of their
+// members is kept. JSON arrays are []interface{}, and everything else is the same as with Value.
+// The value is not copied.
+// If the document was decoded lazily (see DecoderOptions.Lazy), everything in the value is decoded.
+// Nodes cache the value of their parent, so to change an element of a []interface{} in the value,
+// use the methods of JSONNode rather than assigning to it; otherwise other nodes may not see the change.
+func (jn *JSONNode) OrderedValue() interface{} {
+	value, _ := jn.lookup()
+
+	return value
+}
+
+// lookup gets the value of this node, and whether it exists.
+// A child node stops existing if its parent is modified out from under it.
+// If the document was decoded lazily, everything in the value is decoded first.
+func (jn *JSONNode) lookup() (interface{}, bool) {
+	value, ok := jn.lookupShallow()
+	if !ok {
+		return nil, false
+	}
+
+	if jn.doc != nil && jn.doc.lazy {
+		value = materialize(value)
+
+		if jn.parent == nil {
+			// Everything has been decoded
+			jn.doc.lazy = false
+		}
+	}
+
+	return value, true
+}
+
+// shallowValue gets the value of this node like Value does, except that anything under it in a
+// lazily decoded document may not have been decoded yet.
+func (jn *JSONNode) shallowValue() interface{} {
+	value, _ := jn.lookupShallow()
+
+	return value
+}
+
+// lookupShallow gets the value of this node, and whether it exists, like lookup.
+// If the document was decoded lazily, only the value itself is decoded, not anything under it.
+func (jn *JSONNode) lookupShallow() (interface{}, bool) {
+	value, ok := jn.lookupRaw()
+	if !ok {
+		return nil, false
+	}
+
+	value, expanded := expand(value)
+	if expanded {
+		switch value.(type) {
+		case *Object, []interface{}:
+			// Keep the decoded object or array, so it's only decoded once and changes to it are kept.
+			// Anything else is left as it was, so that reading it doesn't change how it is marshalled
+			// (1.10 stays 1.10).
+			// It replaces the same value, so nothing cached is out of date.
+			_ = jn.storeValue(value, false)
+		}
+	}
+
+	if obj, ok := value.(*Object); ok {
+		obj.adopt(jn.doc)
+	}
+
+	return value, true
+}
+
+// lookupRaw gets the value of this node, and whether it exists, without decoding it if the
+// document was decoded lazily and it hasn't been yet.
+func (jn *JSONNode) lookupRaw() (interface{}, bool) {
+	if jn == nil {
+		return nil, false
+	}
+
+	if jn.parent != nil {
 		// The actual value for this is in the parent (this is not the root node)
-		val := jn.parent.Value()
+		val := jn.parentValue()
 
 		if jn.index >= 0 {
 			// This node is an item in an array
-			return val.([]interface{})[jn.index]
+			valSlice, ok := val.([]interface{})
+			if !ok || jn.index >= len(valSlice) {
+				// The array was modified out from under this node
+				return nil, false
+			}
+
+			return valSlice[jn.index], true
+		}
+
+		// This node is a field on a struct
+		obj, ok := val.(*Object)
+		if !ok {
+			return nil, false
+		}
+
+		return obj.Get(jn.fieldName)
+	}
+
+	// The data is directly contained in this node (this is the root node)
+	return jn.data, true
+}
+
+// setValue replaces the value of this node, writing it through to wherever the value is held.
+func (jn *JSONNode) setValue(value interface{}) error {
+	return jn.storeValue(value, true)
+}
+
+// storeValue is like setValue. If invalidate is false, nodes are not told about the change.
+func (jn *JSONNode) storeValue(value interface{}, invalidate bool) error {
+	if jn == nil {
+		return ErrNilNode
+	}
+
+	if jn.parent == nil {
+		jn.data = value
+
+		if invalidate {
+			jn.doc.invalidate()
 		}
 
-		// This node is not an item in an array
+		return nil
+	}
 
-		valMap := val.(map[string]interface{})
+	val := jn.parentValue()
 
-		if len(jn.fieldName) == 0 {
-			// There's no field name specified (probably trying to get a struct in an array)
-			return valMap
+	if jn.index >= 0 {
+		valSlice, ok := val.([]interface{})
+		if !ok {
+			return ErrNotArray
 		}
 
-		// This node is a field on a struct
-		return valMap[jn.fieldName]
+		if jn.index >= len(valSlice) {
+			return ErrIndexOutOfRange
+		}
+
+		valSlice[jn.index] = value
+
+		if invalidate {
+			jn.doc.invalidate()
+		}
+
+		return nil
+	}
+
+	obj, ok := val.(*Object)
+	if !ok {
+		return ErrNotObject
 	}
 
-	// The data is directly contained in this node (this is probably the root node)
-	return jn.data
+	if invalidate {
+		obj.Set(jn.fieldName, value)
+	} else {
+		obj.set(jn.fieldName, value)
+	}
+
+	return nil
 }
 
 // ValueAsNode gets the value of a field as a *JSONNode.
 // This is useful for when the value is a JSON struct in an array element.
 func (jn *JSONNode) ValueAsNode() (*JSONNode, bool) {
-	_, ok := jn.Value().(map[string]interface{})
+	_, ok := jn.shallowValue().(*Object)
 	if !ok {
 		return nil, false
 	}
 
-	node := newChild(jn, "")
-
-	return node, true
+	// This node already refers to the JSON object, so it can be used as-is
+	return jn, true
 }
 
 // ValueAsString gets the value of the current node as string
 func (jn *JSONNode) ValueAsString() (string, bool) {
-	val, ok := jn.Value().(string)
+	val, ok := jn.OrderedValue().(string)
+
+	return val, ok
+}
+
+// ValueAsBool gets the value of the current node as a bool
+func (jn *JSONNode) ValueAsBool() (bool, bool) {
+	val, ok := jn.OrderedValue().(bool)
 
 	return val, ok
 }
 
 // ValueAsFloat64 gets the value of the current node as a float64.
 // Golangs stdlib will unmarshal any numeric JSON object as a float64, so that's what you get.
+// A json.Number (see DecoderOptions.UseNumber) is converted, which may round it.
 func (jn *JSONNode) ValueAsFloat64() (float64, bool) {
-	val, ok := jn.Value().(float64)
-
-	return val, ok
+	return numberFloat64(jn.OrderedValue())
 }
 
 // ValueAsSlice returns the value of the current node as a []*JSONNode.
 func (jn *JSONNode) ValueAsSlice() ([]*JSONNode, bool) {
-	val, ok := jn.Value().([]interface{})
+	gen := jn.doc.generation()
+
+	value := jn.shallowValue()
+
+	val, ok := value.([]interface{})
 	if !ok {
 		return nil, false
 	}
@@ -157,11 +404,122 @@ func (jn *JSONNode) ValueAsSlice() ([]*JSONNode, bool) {
 	nodes := make([]*JSONNode, len(val))
 
 	for i := range val {
-		node := newChild(jn, jn.fieldName)
-		node.index = i
-
-		nodes[i] = node
+		nodes[i] = newElement(jn, i, value, gen)
 	}
 
 	return nodes, true
 }
+
+// Set sets the specified field of this JSON object to a copy of value, adding the field to the end
+// of the object if it does not exist.
+// value can be anything FromValue accepts, such as a struct; it is converted the same way.
+// The change is visible through the root node and any other *JSONNode referring to the same data.
+func (jn *JSONNode) Set(fieldName string, value interface{}) error {
+	if jn == nil {
+		return ErrNilNode
+	}
+
+	obj, ok := jn.shallowValue().(*Object)
+	if !ok {
+		return ErrNotObject
+	}
+
+	val, err := toValue(value)
+	if err != nil {
+		return err
+	}
+
+	obj.Set(fieldName, val)
+
+	return nil
+}
+
+// Delete removes the specified field from this JSON object.
+// Deleting a field that does not exist is not an error.
+func (jn *JSONNode) Delete(fieldName string) error {
+	if jn == nil {
+		return ErrNilNode
+	}
+
+	obj, ok := jn.shallowValue().(*Object)
+	if !ok {
+		return ErrNotObject
+	}
+
+	obj.Delete(fieldName)
+
+	return nil
+}
+
+// Append adds a copy of value to the end of this JSON array.
+func (jn *JSONNode) Append(value interface{}) error {
+	if jn == nil {
+		return ErrNilNode
+	}
+
+	valSlice, ok := jn.shallowValue().([]interface{})
+	if !ok {
+		return ErrNotArray
+	}
+
+	val, err := toValue(value)
+	if err != nil {
+		return err
+	}
+
+	return jn.setValue(append(valSlice, val))
+}
+
+// InsertAt inserts a copy of value into this JSON array at index i, shifting any following elements up by one.
+// i may be equal to the length of the array, in which case this is the same as Append.
+func (jn *JSONNode) InsertAt(i int, value interface{}) error {
+	if jn == nil {
+		return ErrNilNode
+	}
+
+	valSlice, ok := jn.shallowValue().([]interface{})
+	if !ok {
+		return ErrNotArray
+	}
+
+	if i < 0 || i > len(valSlice) {
+		return ErrIndexOutOfRange
+	}
+
+	val, err := toValue(value)
+	if err != nil {
+		return err
+	}
+
+	// Build a new slice rather than shifting in place, so any slices previously returned by Value
+	// are left alone.
+	inserted := make([]interface{}, 0, len(valSlice)+1)
+	inserted = append(inserted, valSlice[:i]...)
+	inserted = append(inserted, val)
+	inserted = append(inserted, valSlice[i:]...)
+
+	return jn.setValue(inserted)
+}
+
+// RemoveAt removes the element at index i from this JSON array, shifting any following elements down by one.
+func (jn *JSONNode) RemoveAt(i int) error {
+	if jn == nil {
+		return ErrNilNode
+	}
+
+	valSlice, ok := jn.shallowValue().([]interface{})
+	if !ok {
+		return ErrNotArray
+	}
+
+	if i < 0 || i >= len(valSlice) {
+		return ErrIndexOutOfRange
+	}
+
+	// As with InsertAt, build a new slice rather than shifting in place.
+	removed := make([]interface{}, 0, len(valSlice)-1)
+	removed = append(removed, valSlice[:i]...)
+	removed = append(removed, valSlice[i+1:]...)
+
+	return jn.setValue(removed)
+}
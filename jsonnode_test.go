@@ -158,6 +158,36 @@ func TestJSONNodeUnmarshal(t *testing.T) {
 		require.Equal(t, "prosciutto", val)
 	})
 
+	t.Run("keys", func(t *testing.T) {
+		t.Parallel()
+
+		jn := new(JSONNode)
+		err := json.Unmarshal([]byte(raw), jn)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"platter", "cheeses", "with"}, jn.Keys())
+		require.Equal(t, []string{"fruit", "meat"}, jn.Get("with").Keys())
+		require.Nil(t, jn.Get("cheeses").Keys())
+	})
+
+	t.Run("value", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+
+		var expected interface{}
+		require.NoError(t, json.Unmarshal([]byte(platterJSON), &expected))
+		require.Equal(t, expected, jn.Value())
+
+		// Changing the value does not change the document
+		jn.Value().(map[string]interface{})["platter"] = "wood"
+		jn.Get("with").Value().(map[string]interface{})["fruit"].([]interface{})[0] = "none"
+		require.Equal(t, "slate", jn.Get("platter").Value())
+		require.Equal(t, "grapes", jn.Pointer("/with/fruit/0/type").Value())
+
+		require.Equal(t, []string{"platter", "cheeses", "with"}, jn.OrderedValue().(*Object).Keys())
+	})
+
 	t.Run("array of simple type", func(t *testing.T) {
 		t.Parallel()
 
@@ -237,6 +267,299 @@ func TestJSONNodeUnmarshal(t *testing.T) {
 func TestJSONNodeMarshal(t *testing.T) {
 	t.Parallel()
 
+	t.Run("root values", func(t *testing.T) {
+		t.Parallel()
+
+		for _, raw := range []string{
+			`{"a":1}`,
+			`[1,"two",{"three":3}]`,
+			`"just a string"`,
+			`12.5`,
+			`true`,
+			`null`,
+		} {
+			jn := new(JSONNode)
+			err := json.Unmarshal([]byte(raw), jn)
+			require.NoError(t, err, raw)
+
+			data, err := json.Marshal(jn)
+			require.NoError(t, err, raw)
+			require.JSONEq(t, raw, string(data))
+		}
+	})
+
+	t.Run("root array", func(t *testing.T) {
+		t.Parallel()
+
+		jn := new(JSONNode)
+		err := json.Unmarshal([]byte(`[{"id": 1}, {"id": 2}]`), jn)
+		require.NoError(t, err)
+
+		nodes, ok := jn.ValueAsSlice()
+		require.True(t, ok)
+		require.Len(t, nodes, 2)
+
+		id, ok := nodes[1].Get("id").ValueAsFloat64()
+		require.True(t, ok)
+		require.Equal(t, float64(2), id)
+
+		require.NoError(t, jn.Append(map[string]interface{}{"id": float64(3)}))
+		require.NoError(t, nodes[0].Set("id", float64(0)))
+
+		data, err := json.Marshal(jn)
+		require.NoError(t, err)
+		require.JSONEq(t, `[{"id": 0}, {"id": 2}, {"id": 3}]`, string(data))
+	})
+
+	t.Run("key order", func(t *testing.T) {
+		t.Parallel()
+
+		raw := `{"zebra":1,"apple":{"z":true,"a":false},"mango":[{"b":1,"a":2}]}`
+
+		jn := new(JSONNode)
+		err := json.Unmarshal([]byte(raw), jn)
+		require.NoError(t, err)
+
+		data, err := json.Marshal(jn)
+		require.NoError(t, err)
+		require.Equal(t, raw, string(data))
+
+		// New keys go at the end, existing keys stay where they are
+		require.NoError(t, jn.Set("banana", "new"))
+		require.NoError(t, jn.Set("zebra", float64(2)))
+
+		// Deleting and re-adding moves a key to the end
+		require.NoError(t, jn.Get("apple").Delete("z"))
+		require.NoError(t, jn.Get("apple").Set("z", true))
+
+		data, err = json.Marshal(jn)
+		require.NoError(t, err)
+		require.Equal(t, `{"zebra":2,"apple":{"a":false,"z":true},"mango":[{"b":1,"a":2}],"banana":"new"}`, string(data))
+
+		require.Equal(t, []string{"zebra", "apple", "mango", "banana"}, jn.Keys())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		for _, raw := range []string{
+			``,
+			`{`,
+			`{"a" 1}`,
+			`[1,]`,
+			`{} {}`,
+			`1 2`,
+		} {
+			jn := new(JSONNode)
+			err := jn.UnmarshalJSON([]byte(raw))
+			require.Error(t, err, raw)
+		}
+	})
+
+	t.Run("from value", func(t *testing.T) {
+		t.Parallel()
+
+		jn := NewFromValue([]interface{}{"a", float64(1)})
+
+		require.NoError(t, jn.InsertAt(0, nil))
+
+		data, err := json.Marshal(jn)
+		require.NoError(t, err)
+		require.JSONEq(t, `[null, "a", 1]`, string(data))
+
+		str, ok := NewFromValue("scalar").ValueAsString()
+		require.True(t, ok)
+		require.Equal(t, "scalar", str)
+	})
+}
+
+// platterJSON is the document used by most of the tests.
+const platterJSON = `{
+    "platter": "slate",
+    "cheeses": ["cheddar", "swiss", "manchego"],
+    "with": {
+        "fruit": [{
+                "type": "grapes",
+                "count": 8
+            },
+            {
+                "type": "strawberries",
+                "count": 3
+            }
+        ],
+        "meat": "prosciutto"
+    }
+}`
+
+// unmarshalPlatter unmarshals platterJSON into a new node.
+func unmarshalPlatter(t *testing.T) *JSONNode {
+	return unmarshalString(t, platterJSON)
+}
+
+// unmarshalString unmarshals data into a new node.
+func unmarshalString(t *testing.T, data string) *JSONNode {
+	jn := new(JSONNode)
+	require.NoError(t, json.Unmarshal([]byte(data), jn), data)
+
+	return jn
+}
+
+// unmarshalNumbers unmarshals data into a new node, with numbers decoded as json.Number.
+func unmarshalNumbers(t *testing.T, data string) *JSONNode {
+	jn, err := Unmarshal([]byte(data), DecoderOptions{UseNumber: true})
+	require.NoError(t, err, data)
+
+	return jn
+}
+
+// marshal marshals v, which must succeed.
+func marshal(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+func TestJSONNodeMutate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("set and delete", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+
+		err := jn.Set("platter", "wood")
+		require.NoError(t, err)
+
+		platter, ok := jn.Get("platter").ValueAsString()
+		require.True(t, ok)
+		require.Equal(t, "wood", platter)
+
+		// Set on a child writes through to the root
+		with := jn.Get("with")
+		err = with.Set("bread", "baguette")
+		require.NoError(t, err)
+
+		bread, ok := jn.Get("with").Get("bread").ValueAsString()
+		require.True(t, ok)
+		require.Equal(t, "baguette", bread)
+
+		err = with.Delete("meat")
+		require.NoError(t, err)
+		require.Nil(t, jn.Get("with").Get("meat"))
+
+		// Deleting something that isn't there is fine
+		err = with.Delete("meat")
+		require.NoError(t, err)
+
+		// Can't set fields on things that aren't objects
+		err = jn.Get("platter").Set("color", "black")
+		require.Equal(t, ErrNotObject, err)
+
+		err = jn.Get("cheeses").Delete("cheddar")
+		require.Equal(t, ErrNotObject, err)
+
+		var nilNode *JSONNode
+		require.Equal(t, ErrNilNode, nilNode.Set("a", 1))
+	})
+
+	t.Run("empty field name", func(t *testing.T) {
+		t.Parallel()
+
+		jn := New()
+		require.NoError(t, jn.Set("", "empty"))
+
+		empty, ok := jn.Get("").ValueAsString()
+		require.True(t, ok)
+		require.Equal(t, "empty", empty)
+	})
+
+	t.Run("set on array element", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+
+		fruit, ok := jn.Get("with").Get("fruit").ValueAsSlice()
+		require.True(t, ok)
+
+		err := fruit[1].Set("count", float64(12))
+		require.NoError(t, err)
+
+		fruit, ok = jn.Get("with").Get("fruit").ValueAsSlice()
+		require.True(t, ok)
+
+		count, ok := fruit[1].Get("count").ValueAsFloat64()
+		require.True(t, ok)
+		require.Equal(t, float64(12), count)
+	})
+
+	t.Run("array changes", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+		cheeses := jn.Get("cheeses")
+
+		err := cheeses.Append("gouda")
+		require.NoError(t, err)
+
+		err = cheeses.InsertAt(0, "brie")
+		require.NoError(t, err)
+
+		err = cheeses.InsertAt(5, "feta")
+		require.NoError(t, err)
+
+		err = cheeses.RemoveAt(2)
+		require.NoError(t, err)
+
+		require.Equal(t, []interface{}{"brie", "cheddar", "manchego", "gouda", "feta"}, jn.Get("cheeses").Value())
+
+		require.Equal(t, ErrIndexOutOfRange, cheeses.InsertAt(6, "edam"))
+		require.Equal(t, ErrIndexOutOfRange, cheeses.InsertAt(-1, "edam"))
+		require.Equal(t, ErrIndexOutOfRange, cheeses.RemoveAt(5))
+		require.Equal(t, ErrNotArray, jn.Get("with").Append("bread"))
+
+		// Arrays nested in arrays
+		err = cheeses.Append([]interface{}{})
+		require.NoError(t, err)
+
+		nodes, ok := cheeses.ValueAsSlice()
+		require.True(t, ok)
+
+		err = nodes[5].Append("blue")
+		require.NoError(t, err)
+		require.Equal(t, []interface{}{"blue"}, jn.Get("cheeses").Value().([]interface{})[5])
+	})
+
+	t.Run("removed element", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+
+		nodes, ok := jn.Get("cheeses").ValueAsSlice()
+		require.True(t, ok)
+
+		err := jn.Get("cheeses").RemoveAt(0)
+		require.NoError(t, err)
+
+		// The node for the last element now refers to something that isn't there
+		require.NotPanics(t, func() {
+			require.Nil(t, nodes[2].Value())
+		})
+	})
+
+	t.Run("marshal after changes", func(t *testing.T) {
+		t.Parallel()
+
+		jn := New()
+		require.NoError(t, jn.Set("list", []interface{}{}))
+		require.NoError(t, jn.Get("list").Append("a"))
+		require.NoError(t, jn.Set("obj", map[string]interface{}{}))
+		require.NoError(t, jn.Get("obj").Set("b", true))
+
+		data, err := json.Marshal(jn)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"list": ["a"], "obj": {"b": true}}`, string(data))
+	})
 }
 
 func ExampleJSONNode_thorough() {
@@ -512,3 +835,77 @@ func ExampleJSONNode_ValueAsNode() {
 	//     8 grapes
 	//     3 strawberries
 }
+
+func ExampleJSONNode_Value() {
+	jn := new(JSONNode)
+	err := json.Unmarshal([]byte(`{"platter": "slate", "cheese": "swiss"}`), jn)
+	if err != nil {
+		panic(err)
+	}
+
+	// JSON objects are map[string]interface{}, the same as with encoding/json
+	fmt.Println(jn.Value().(map[string]interface{})["cheese"])
+
+	// Use OrderedValue to keep the members of JSON objects in order
+	fmt.Println(jn.OrderedValue().(*Object).Keys())
+
+	// Output:
+	// swiss
+	// [platter cheese]
+}
+
+func BenchmarkJSONNodeValue(b *testing.B) {
+	const depth = 50
+
+	// {"a": {"a": ... {"a": {"items": [{"id": 0}, {"id": 1}, ...]}}}}
+	items := make([]interface{}, 1000)
+	for i := range items {
+		items[i] = map[string]interface{}{"id": float64(i)}
+	}
+
+	var value interface{} = map[string]interface{}{"items": items}
+	for i := 0; i < depth; i++ {
+		value = map[string]interface{}{"a": value}
+	}
+
+	jn := NewFromValue(value)
+
+	deep := jn
+	for i := 0; i < depth; i++ {
+		deep = deep.Get("a")
+	}
+
+	b.Run("deep", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if !deep.IsObject() {
+				b.Fatal("not an object")
+			}
+		}
+	})
+
+	b.Run("deep and wide", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			elements, _ := deep.Get("items").ValueAsSlice()
+
+			for _, element := range elements {
+				if _, ok := element.Get("id").ValueAsFloat64(); !ok {
+					b.Fatal("not a number")
+				}
+			}
+		}
+	})
+
+	b.Run("wide", func(b *testing.B) {
+		wide := NewFromValue(items)
+
+		for i := 0; i < b.N; i++ {
+			elements, _ := wide.ValueAsSlice()
+
+			for _, element := range elements {
+				if _, ok := element.Get("id").ValueAsFloat64(); !ok {
+					b.Fatal("not a number")
+				}
+			}
+		}
+	})
+}
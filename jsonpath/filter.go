@@ -0,0 +1,261 @@
+package jsonpath
+
+import (
+	"encoding/json"
+
+	"github.com/dcormier/go-jsonnode"
+)
+
+// expr is part of a filter expression.
+type expr interface{}
+
+// nothing is the result of a value expression that has no value (as opposed to a JSON null).
+type nothing struct{}
+
+// nodeList is the values of the nodes selected by a query in a filter expression.
+type nodeList []interface{}
+
+type literalExpr struct {
+	value interface{}
+}
+
+type queryExpr struct {
+	absolute bool
+	segments []segment
+	singular bool
+}
+
+type funcExpr struct {
+	name string
+	fn   *function
+	args []expr
+}
+
+type comparisonExpr struct {
+	op          string
+	left, right expr
+}
+
+type andExpr []expr
+
+type orExpr []expr
+
+type notExpr struct {
+	operand expr
+}
+
+type parenExpr struct {
+	inner expr
+}
+
+// evalLogical evaluates e as a logical expression, with current as the current node ("@").
+func (ctx *evalContext) evalLogical(e expr, current interface{}) bool {
+	switch e := e.(type) {
+	case *comparisonExpr:
+		return compare(e.op, ctx.evalValue(e.left, current), ctx.evalValue(e.right, current))
+
+	case andExpr:
+		for _, operand := range e {
+			if !ctx.evalLogical(operand, current) {
+				return false
+			}
+		}
+
+		return true
+
+	case orExpr:
+		for _, operand := range e {
+			if ctx.evalLogical(operand, current) {
+				return true
+			}
+		}
+
+		return false
+
+	case notExpr:
+		return !ctx.evalLogical(e.operand, current)
+
+	case *parenExpr:
+		return ctx.evalLogical(e.inner, current)
+
+	case *queryExpr:
+		return len(ctx.evalQuery(e, current)) > 0
+
+	case *funcExpr:
+		result := ctx.call(e, current)
+
+		if nodes, ok := result.(nodeList); ok {
+			return len(nodes) > 0
+		}
+
+		return result.(bool)
+	}
+
+	return false
+}
+
+// evalValue evaluates e as a value, with current as the current node ("@").
+// nothing{} is returned if there is no value.
+func (ctx *evalContext) evalValue(e expr, current interface{}) interface{} {
+	switch e := e.(type) {
+	case literalExpr:
+		return e.value
+
+	case *queryExpr:
+		nodes := ctx.evalQuery(e, current)
+		if len(nodes) != 1 {
+			return nothing{}
+		}
+
+		return nodes[0]
+
+	case *funcExpr:
+		return ctx.call(e, current)
+	}
+
+	return nothing{}
+}
+
+// evalQuery evaluates a query inside a filter expression.
+func (ctx *evalContext) evalQuery(e *queryExpr, current interface{}) nodeList {
+	start := current
+	if e.absolute {
+		start = ctx.root
+	}
+
+	// Nodes aren't needed here, only values
+	queryCtx := ctx
+	if ctx.trackNodes {
+		queryCtx = &evalContext{root: ctx.root}
+	}
+
+	items := queryCtx.evalSegments(e.segments, []item{{value: start}})
+
+	nodes := make(nodeList, len(items))
+	for i := range items {
+		nodes[i] = items[i].value
+	}
+
+	return nodes
+}
+
+func (ctx *evalContext) call(e *funcExpr, current interface{}) interface{} {
+	args := make([]interface{}, len(e.args))
+
+	for i, arg := range e.args {
+		switch e.fn.params[i] {
+		case valueType:
+			args[i] = ctx.evalValue(arg, current)
+
+		case logicalType:
+			args[i] = ctx.evalLogical(arg, current)
+
+		case nodesType:
+			switch arg := arg.(type) {
+			case *queryExpr:
+				args[i] = ctx.evalQuery(arg, current)
+
+			case *funcExpr:
+				args[i] = ctx.call(arg, current)
+			}
+		}
+	}
+
+	return e.fn.call(args)
+}
+
+// compare applies a comparison operator to two values, either of which may be nothing{}.
+func compare(op string, left, right interface{}) bool {
+	switch op {
+	case "==":
+		return equal(left, right)
+
+	case "!=":
+		return !equal(left, right)
+
+	case "<":
+		return less(left, right)
+
+	case "<=":
+		return less(left, right) || equal(left, right)
+
+	case ">":
+		return less(right, left)
+
+	case ">=":
+		return less(right, left) || equal(left, right)
+	}
+
+	return false
+}
+
+func equal(left, right interface{}) bool {
+	switch l := left.(type) {
+	case nothing:
+		_, ok := right.(nothing)
+		return ok
+
+	case nil:
+		return right == nil
+
+	case bool:
+		r, ok := right.(bool)
+		return ok && l == r
+
+	case float64, json.Number:
+		cmp, ok := jsonnode.CompareNumbers(l, right)
+		return ok && cmp == 0
+
+	case string:
+		r, ok := right.(string)
+		return ok && l == r
+
+	case []interface{}:
+		r, ok := right.([]interface{})
+		if !ok || len(l) != len(r) {
+			return false
+		}
+
+		for i := range l {
+			if !equal(l[i], r[i]) {
+				return false
+			}
+		}
+
+		return true
+
+	case *jsonnode.Object:
+		r, ok := right.(*jsonnode.Object)
+		if !ok || l.Len() != r.Len() {
+			return false
+		}
+
+		for _, key := range l.Keys() {
+			lv, _ := l.Get(key)
+
+			rv, ok := r.Get(key)
+			if !ok || !equal(lv, rv) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func less(left, right interface{}) bool {
+	switch l := left.(type) {
+	case float64, json.Number:
+		cmp, ok := jsonnode.CompareNumbers(l, right)
+		return ok && cmp < 0
+
+	case string:
+		// Comparing the UTF-8 bytes gives the same result as comparing Unicode scalar values
+		r, ok := right.(string)
+		return ok && l < r
+	}
+
+	return false
+}
@@ -0,0 +1,185 @@
+package jsonpath
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dcormier/go-jsonnode"
+)
+
+// paramType is the type of a function parameter or result, as defined by RFC 9535.
+type paramType int
+
+const (
+	valueType paramType = iota
+	logicalType
+	nodesType
+)
+
+func (t paramType) String() string {
+	switch t {
+	case valueType:
+		return "a value"
+
+	case logicalType:
+		return "a logical expression"
+
+	case nodesType:
+		return "a query"
+	}
+
+	return "unknown"
+}
+
+// function is a function extension that can be used in filter expressions.
+// Arguments are passed as a value (possibly nothing{}), a bool, or a nodeList, according to params.
+type function struct {
+	params []paramType
+	result paramType
+	call   func(args []interface{}) interface{}
+}
+
+// functions are the function extensions defined by RFC 9535.
+var functions = map[string]*function{
+	"length": {
+		params: []paramType{valueType},
+		result: valueType,
+		call:   fnLength,
+	},
+	"count": {
+		params: []paramType{nodesType},
+		result: valueType,
+		call:   fnCount,
+	},
+	"match": {
+		params: []paramType{valueType, valueType},
+		result: logicalType,
+		call:   fnMatch,
+	},
+	"search": {
+		params: []paramType{valueType, valueType},
+		result: logicalType,
+		call:   fnSearch,
+	},
+	"value": {
+		params: []paramType{nodesType},
+		result: valueType,
+		call:   fnValue,
+	},
+}
+
+func fnLength(args []interface{}) interface{} {
+	switch v := args[0].(type) {
+	case string:
+		return float64(utf8.RuneCountInString(v))
+
+	case []interface{}:
+		return float64(len(v))
+
+	case *jsonnode.Object:
+		return float64(v.Len())
+	}
+
+	return nothing{}
+}
+
+func fnCount(args []interface{}) interface{} {
+	return float64(len(args[0].(nodeList)))
+}
+
+func fnMatch(args []interface{}) interface{} {
+	return regexpCall(args, true)
+}
+
+func fnSearch(args []interface{}) interface{} {
+	return regexpCall(args, false)
+}
+
+func regexpCall(args []interface{}, whole bool) interface{} {
+	s, ok := args[0].(string)
+	if !ok {
+		return false
+	}
+
+	var re *regexp.Regexp
+
+	switch pattern := args[1].(type) {
+	case *regexp.Regexp:
+		// A literal pattern, compiled when the path was parsed
+		re = pattern
+
+	case string:
+		re = compileIRegexp(pattern, whole)
+	}
+
+	if re == nil {
+		return false
+	}
+
+	return re.MatchString(s)
+}
+
+func fnValue(args []interface{}) interface{} {
+	nodes := args[0].(nodeList)
+	if len(nodes) != 1 {
+		return nothing{}
+	}
+
+	return nodes[0]
+}
+
+// compileIRegexp compiles an I-Regexp (RFC 9485) into the equivalent Go regular expression.
+// If whole is true, the expression must match the whole string.
+// nil is returned if the pattern is not valid.
+func compileIRegexp(pattern string, whole bool) *regexp.Regexp {
+	var sb strings.Builder
+	inClass := false
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+
+		switch {
+		case c == '\\':
+			sb.WriteByte(c)
+
+			if i+1 < len(pattern) {
+				i++
+				sb.WriteByte(pattern[i])
+			}
+
+			continue
+
+		case inClass:
+			if c == ']' {
+				inClass = false
+			}
+
+		case c == '[':
+			inClass = true
+
+		case c == '.':
+			// In I-Regexp, '.' matches anything but line endings.
+			sb.WriteString(`[^\n\r]`)
+			continue
+
+		case c == '^' || c == '$':
+			// These are not anchors in I-Regexp.
+			sb.WriteByte('\\')
+		}
+
+		sb.WriteByte(c)
+	}
+
+	expr := sb.String()
+	if whole {
+		expr = `^(?:` + expr + `)$`
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil
+	}
+
+	return re
+}
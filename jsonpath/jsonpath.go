@@ -0,0 +1,299 @@
+// Package jsonpath implements JSONPath (RFC 9535) queries over a *jsonnode.JSONNode.
+//
+// The result of a query is a list of *jsonnode.JSONNode that still refer to the document that was
+// queried, so they can be used to read or modify the matched values in place.
+package jsonpath
+
+import "github.com/dcormier/go-jsonnode"
+
+// Path is a compiled JSONPath query. It is safe for concurrent use.
+type Path struct {
+	expr     string
+	segments []segment
+}
+
+// Compile parses a JSONPath query so it can be used to query any number of documents.
+func Compile(expr string) (*Path, error) {
+	p := &parser{expr: expr}
+
+	segments, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Path{expr: expr, segments: segments}, nil
+}
+
+// MustCompile is like Compile, but panics if the query cannot be parsed.
+func MustCompile(expr string) *Path {
+	path, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+
+	return path
+}
+
+// Query compiles the JSONPath query and runs it against jn.
+func Query(jn *jsonnode.JSONNode, expr string) ([]*jsonnode.JSONNode, error) {
+	path, err := Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return path.Query(jn), nil
+}
+
+// String returns the query this Path was compiled from.
+func (path *Path) String() string {
+	return path.expr
+}
+
+// Query runs this query against jn, which is used as the root ("$") of the query.
+// The matching nodes are returned in the order defined by RFC 9535. Members of JSON objects are
+// visited in the order they are in the object.
+func (path *Path) Query(jn *jsonnode.JSONNode) []*jsonnode.JSONNode {
+	if jn == nil {
+		return nil
+	}
+
+	root := jn.OrderedValue()
+	ctx := &evalContext{root: root, trackNodes: true}
+
+	items := ctx.evalSegments(path.segments, []item{{node: jn, value: root}})
+	if len(items) == 0 {
+		return nil
+	}
+
+	nodes := make([]*jsonnode.JSONNode, len(items))
+	for i := range items {
+		nodes[i] = items[i].node
+	}
+
+	return nodes
+}
+
+// item is a node being worked on while evaluating a query.
+type item struct {
+	// node is only tracked for the top-level query. Queries inside filters only need values.
+	node  *jsonnode.JSONNode
+	value interface{}
+}
+
+type evalContext struct {
+	root       interface{}
+	trackNodes bool
+}
+
+func (ctx *evalContext) member(parent item, name string, value interface{}) item {
+	if !ctx.trackNodes {
+		return item{value: value}
+	}
+
+	return item{node: parent.node.Get(name), value: value}
+}
+
+func (ctx *evalContext) element(parent item, i int, value interface{}) item {
+	if !ctx.trackNodes {
+		return item{value: value}
+	}
+
+	return item{node: parent.node.Index(i), value: value}
+}
+
+// children calls fn for each child of it, in order.
+func (ctx *evalContext) children(it item, fn func(child item)) {
+	switch v := it.value.(type) {
+	case *jsonnode.Object:
+		for _, key := range v.Keys() {
+			value, _ := v.Get(key)
+			fn(ctx.member(it, key, value))
+		}
+
+	case []interface{}:
+		for i := range v {
+			fn(ctx.element(it, i, v[i]))
+		}
+	}
+}
+
+// descendants calls fn for it and each of its descendants, with nodes before their descendants.
+func (ctx *evalContext) descendants(it item, fn func(item)) {
+	fn(it)
+
+	ctx.children(it, func(child item) {
+		ctx.descendants(child, fn)
+	})
+}
+
+func (ctx *evalContext) evalSegments(segments []segment, items []item) []item {
+	for _, seg := range segments {
+		var next []item
+
+		for _, it := range items {
+			if !seg.descendant {
+				next = seg.apply(ctx, it, next)
+				continue
+			}
+
+			ctx.descendants(it, func(d item) {
+				next = seg.apply(ctx, d, next)
+			})
+		}
+
+		items = next
+	}
+
+	return items
+}
+
+type segment struct {
+	descendant bool
+	selectors  []selector
+}
+
+func (seg segment) apply(ctx *evalContext, it item, out []item) []item {
+	for _, sel := range seg.selectors {
+		out = sel.apply(ctx, it, out)
+	}
+
+	return out
+}
+
+// selector selects children of a node.
+type selector interface {
+	apply(ctx *evalContext, it item, out []item) []item
+}
+
+type nameSelector string
+
+func (sel nameSelector) apply(ctx *evalContext, it item, out []item) []item {
+	obj, ok := it.value.(*jsonnode.Object)
+	if !ok {
+		return out
+	}
+
+	value, ok := obj.Get(string(sel))
+	if !ok {
+		return out
+	}
+
+	return append(out, ctx.member(it, string(sel), value))
+}
+
+type wildcardSelector struct{}
+
+func (wildcardSelector) apply(ctx *evalContext, it item, out []item) []item {
+	ctx.children(it, func(child item) {
+		out = append(out, child)
+	})
+
+	return out
+}
+
+type indexSelector int64
+
+func (sel indexSelector) apply(ctx *evalContext, it item, out []item) []item {
+	arr, ok := it.value.([]interface{})
+	if !ok {
+		return out
+	}
+
+	i := int64(sel)
+	if i < 0 {
+		i += int64(len(arr))
+	}
+
+	if i < 0 || i >= int64(len(arr)) {
+		return out
+	}
+
+	return append(out, ctx.element(it, int(i), arr[i]))
+}
+
+type sliceSelector struct {
+	start, end       int64
+	hasStart, hasEnd bool
+	step             int64
+}
+
+func (sel sliceSelector) apply(ctx *evalContext, it item, out []item) []item {
+	arr, ok := it.value.([]interface{})
+	if !ok || sel.step == 0 {
+		return out
+	}
+
+	length := int64(len(arr))
+
+	normalize := func(i int64) int64 {
+		if i < 0 {
+			return length + i
+		}
+
+		return i
+	}
+
+	clamp := func(i, lower, upper int64) int64 {
+		if i < lower {
+			return lower
+		}
+
+		if i > upper {
+			return upper
+		}
+
+		return i
+	}
+
+	if sel.step > 0 {
+		start, end := int64(0), length
+
+		if sel.hasStart {
+			start = clamp(normalize(sel.start), 0, length)
+		}
+
+		if sel.hasEnd {
+			end = clamp(normalize(sel.end), 0, length)
+		}
+
+		for i := start; i < end; i += sel.step {
+			out = append(out, ctx.element(it, int(i), arr[i]))
+		}
+
+		return out
+	}
+
+	start, end := length-1, -1-length
+
+	if sel.hasStart {
+		start = clamp(normalize(sel.start), -1, length-1)
+	} else {
+		start = clamp(start, -1, length-1)
+	}
+
+	if sel.hasEnd {
+		end = clamp(normalize(sel.end), -1, length-1)
+	} else {
+		end = clamp(end, -1, length-1)
+	}
+
+	for i := start; i > end; i += sel.step {
+		out = append(out, ctx.element(it, int(i), arr[i]))
+	}
+
+	return out
+}
+
+type filterSelector struct {
+	expr expr
+}
+
+func (sel *filterSelector) apply(ctx *evalContext, it item, out []item) []item {
+	ctx.children(it, func(child item) {
+		if ctx.evalLogical(sel.expr, child.value) {
+			out = append(out, child)
+		}
+	})
+
+	return out
+}
@@ -0,0 +1,340 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/dcormier/go-jsonnode"
+	"github.com/stretchr/testify/require"
+)
+
+func unmarshal(t *testing.T, raw string) *jsonnode.JSONNode {
+	jn := new(jsonnode.JSONNode)
+	err := json.Unmarshal([]byte(raw), jn)
+	require.NoError(t, err)
+
+	return jn
+}
+
+// queryValues runs the query and returns the values of the resulting nodes, as JSON.
+func queryValues(t *testing.T, jn *jsonnode.JSONNode, expr string) string {
+	nodes, err := Query(jn, expr)
+	require.NoError(t, err, expr)
+
+	values := make([]interface{}, len(nodes))
+	for i := range nodes {
+		values[i] = nodes[i].OrderedValue()
+	}
+
+	data, err := json.Marshal(values)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rfc bookstore", func(t *testing.T) {
+		t.Parallel()
+
+		// The example from RFC 9535, section 1.5
+		jn := unmarshal(t, `{ "store": {
+    "book": [
+      { "category": "reference",
+        "author": "Nigel Rees",
+        "title": "Sayings of the Century",
+        "price": 8.95
+      },
+      { "category": "fiction",
+        "author": "Evelyn Waugh",
+        "title": "Sword of Honour",
+        "price": 12.99
+      },
+      { "category": "fiction",
+        "author": "Herman Melville",
+        "title": "Moby Dick",
+        "isbn": "0-553-21311-3",
+        "price": 8.99
+      },
+      { "category": "fiction",
+        "author": "J. R. R. Tolkien",
+        "title": "The Lord of the Rings",
+        "isbn": "0-395-19395-8",
+        "price": 22.99
+      }
+    ],
+    "bicycle": {
+      "color": "red",
+      "price": 399
+    }
+  }
+}`)
+
+		authors := `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`
+
+		for expr, expected := range map[string]string{
+			`$.store.book[*].author`:         authors,
+			`$..author`:                      authors,
+			`$.store..price`:                 `[8.95,12.99,8.99,22.99,399]`,
+			`$..book[2].title`:               `["Moby Dick"]`,
+			`$..book[-1].title`:              `["The Lord of the Rings"]`,
+			`$..book[0,1].title`:             `["Sayings of the Century","Sword of Honour"]`,
+			`$..book[:2].title`:              `["Sayings of the Century","Sword of Honour"]`,
+			`$..book[?@.isbn].title`:         `["Moby Dick","The Lord of the Rings"]`,
+			`$..book[?@.price<10].title`:     `["Sayings of the Century","Moby Dick"]`,
+			`$.store.bicycle.*`:              `["red",399]`,
+			`$["store"]['bicycle']["color"]`: `["red"]`,
+			`$.nope`:                         `[]`,
+		} {
+			require.JSONEq(t, expected, queryValues(t, jn, expr), expr)
+		}
+
+		all, err := Query(jn, `$..*`)
+		require.NoError(t, err)
+		require.Len(t, all, 27)
+	})
+
+	t.Run("rfc filters", func(t *testing.T) {
+		t.Parallel()
+
+		// The example from RFC 9535, section 2.3.5.3
+		jn := unmarshal(t, `{
+  "a": [3, 5, 1, 2, 4, 6,
+        {"b": "j"},
+        {"b": "k"},
+        {"b": {}},
+        {"b": "kilo"}
+       ],
+  "o": {"p": 1, "q": 2, "r": 3, "s": 5, "t": {"u": 6}},
+  "e": "f"
+}`)
+
+		for expr, expected := range map[string]string{
+			`$.a[?@.b == 'kilo']`:          `[{"b": "kilo"}]`,
+			`$.a[?(@.b == 'kilo')]`:        `[{"b": "kilo"}]`,
+			`$.a[?@>3.5]`:                  `[5, 4, 6]`,
+			`$.a[?@.b]`:                    `[{"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}]`,
+			`$[?@.*]`:                      `[[3, 5, 1, 2, 4, 6, {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}], {"p": 1, "q": 2, "r": 3, "s": 5, "t": {"u": 6}}]`,
+			`$[?@[?@.b]]`:                  `[[3, 5, 1, 2, 4, 6, {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}]]`,
+			`$.o[?@<3, ?@<3]`:              `[1, 2, 1, 2]`,
+			`$.a[?@<2 || @.b == "k"]`:      `[1, {"b": "k"}]`,
+			`$.a[?match(@.b, "[jk]")]`:     `[{"b": "j"}, {"b": "k"}]`,
+			`$.a[?search(@.b, "[jk]")]`:    `[{"b": "j"}, {"b": "k"}, {"b": "kilo"}]`,
+			`$.o[?@>1 && @<4]`:             `[2, 3]`,
+			`$.o[?@.u || @.x]`:             `[{"u": 6}]`,
+			`$.a[?@.b == $.x]`:             `[3, 5, 1, 2, 4, 6]`,
+			`$.a[?@ == @]`:                 `[3, 5, 1, 2, 4, 6, {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}]`,
+			`$.a[?!@.b]`:                   `[3, 5, 1, 2, 4, 6]`,
+			`$.a[?!(@ > 1 && @ < 6)]`:      `[1, 6, {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}]`,
+			`$.a[?@.b != 'j' && @.b]`:      `[{"b": "k"}, {"b": {}}, {"b": "kilo"}]`,
+			`$.a[?@ >= 5]`:                 `[5, 6]`,
+			`$.a[?@ <= 2]`:                 `[1, 2]`,
+			`$.a[?@.b > 'j']`:              `[{"b": "k"}, {"b": "kilo"}]`,
+			`$.o[?@ == 1 || @ == 5]`:       `[1, 5]`,
+			`$[?@ == 'f']`:                 `["f"]`,
+			`$.a[?length(@.b) == 4]`:       `[{"b": "kilo"}]`,
+			`$[?length(@) == 5]`:           `[{"p": 1, "q": 2, "r": 3, "s": 5, "t": {"u": 6}}]`,
+			`$[?count(@.*) == 0]`:          `["f"]`,
+			`$.a[?value(@..b) == 'k']`:     `[{"b": "k"}]`,
+			`$.a[?match(@.b, "k.*")]`:      `[{"b": "k"}, {"b": "kilo"}]`,
+			`$.a[?match(@.b, "k.")]`:       `[]`,
+			`$.a[?search(@.b, "^k")]`:      `[]`,
+			`$.a[?match(@.b, "[")]`:        `[]`,
+			`$[?match(@, $.e)]`:            `["f"]`,
+			`$.a[?search(@.b, $.e)]`:       `[]`,
+			`$.a[? @.b  ==  "j" ]`:         `[{"b": "j"}]`,
+			`$.o[?@ == $.o.p]`:             `[1]`,
+			`$.o[?@.u == $["o"].t.u]`:      `[{"u": 6}]`,
+			`$.a[?@ == 1.0e0]`:             `[1]`,
+			`$.a[?@ == -0]`:                `[]`,
+			`$.a[?@ == true || @ == null]`: `[]`,
+		} {
+			require.JSONEq(t, expected, queryValues(t, jn, expr), expr)
+		}
+	})
+
+	t.Run("rfc slices", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshal(t, `["a", "b", "c", "d", "e", "f", "g"]`)
+
+		for expr, expected := range map[string]string{
+			`$[1:3]`:       `["b", "c"]`,
+			`$[5:]`:        `["f", "g"]`,
+			`$[1:5:2]`:     `["b", "d"]`,
+			`$[5:1:-2]`:    `["f", "d"]`,
+			`$[::-1]`:      `["g", "f", "e", "d", "c", "b", "a"]`,
+			`$[:]`:         `["a", "b", "c", "d", "e", "f", "g"]`,
+			`$[-2:]`:       `["f", "g"]`,
+			`$[-100:2]`:    `["a", "b"]`,
+			`$[::0]`:       `[]`,
+			`$[ 1 : 2 : ]`: `["b"]`,
+			`$[0, 0]`:      `["a", "a"]`,
+			`$[7]`:         `[]`,
+			`$[-7]`:        `["a"]`,
+			`$[-8]`:        `[]`,
+			`$[1:3, 5]`:    `["b", "c", "f"]`,
+		} {
+			require.JSONEq(t, expected, queryValues(t, jn, expr), expr)
+		}
+	})
+
+	t.Run("whitespace and names", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshal(t, `{"a b": 1, "ünï": 2, "_x1": 3, "'": 4, "\"": 5, "☺": 6, "𝄞": 7}`)
+
+		for expr, expected := range map[string]string{
+			`$['a b']`:        `[1]`,
+			`$ [ 'a b' ]`:     `[1]`,
+			`$.ünï`:           `[2]`,
+			`$._x1`:           `[3]`,
+			`$["'"]`:          `[4]`,
+			`$['\'']`:         `[4]`,
+			`$['"']`:          `[5]`,
+			`$["\""]`:         `[5]`,
+			`$["☺"]`:          `[6]`,
+			`$["𝄞"]`:          `[7]`,
+			`$["a b", '_x1']`: `[1, 3]`,
+		} {
+			require.JSONEq(t, expected, queryValues(t, jn, expr), expr)
+		}
+	})
+
+	t.Run("json.Number", func(t *testing.T) {
+		t.Parallel()
+
+		jn, err := jsonnode.Unmarshal([]byte(`[{"id": 9007199254740993}, {"id": 9007199254740992}, {"id": 1.50}, {"id": 19.99}]`),
+			jsonnode.DecoderOptions{UseNumber: true})
+		require.NoError(t, err)
+
+		for expr, expected := range map[string]string{
+			`$[?@.id == 9007199254740992].id`:       `[9007199254740992]`,
+			`$[?@.id > 9007199254740992].id`:        `[9007199254740993]`,
+			`$[?@.id == 1.5].id`:                    `[1.50]`,
+			`$[?@.id < 2].id`:                       `[1.50]`,
+			`$[?@.id == $[2].id].id`:                `[1.50]`,
+			`$[?@.id == 19.99].id`:                  `[19.99]`,
+			`$[?@.id > 19.989 && @.id < 19.991].id`: `[19.99]`,
+		} {
+			require.Equal(t, expected, queryValues(t, jn, expr), expr)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		for _, expr := range []string{
+			``,
+			` $`,
+			`$ `,
+			`$.`,
+			`$..`,
+			`@.a`,
+			`$. a`,
+			`$.1a`,
+			`$[01]`,
+			`$[-0]`,
+			`$[1.0]`,
+			`$[9007199254740992]`,
+			`$[]`,
+			`$['a'`,
+			`$['a]`,
+			`$['\x']`,
+			`$['\"']`,
+			`$["\uD834"]`,
+			"$['\x01']",
+			`$[?1]`,
+			`$[?true]`,
+			`$[?@.a == 1 == 2]`,
+			`$[?@.* == 1]`,
+			`$[?@..a == 1]`,
+			`$[?length(@.a)]`,
+			`$[?count(1) == 1]`,
+			`$[?count(@.a, @.b) == 1]`,
+			`$[?match(@.a)]`,
+			`$[?match(@.a, 'a') == true]`,
+			`$[?foo(@)]`,
+			`$[?!1]`,
+			`$[?!!@.a]`,
+			`$[?(1)]`,
+			`$[?@.a == 01]`,
+			`$[?@.a == 1.]`,
+			`$[?@.a == nul]`,
+			`$[?length (@.a) == 1]`,
+			`$[?@.a &&]`,
+			`$[?@.b == {}]`,
+			`$[?value(@.a) == 'a' || 1]`,
+		} {
+			_, err := Compile(expr)
+			require.Error(t, err, expr)
+
+			_, ok := err.(*SyntaxError)
+			require.True(t, ok, "%T", err)
+		}
+	})
+
+	t.Run("nodes are part of the document", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshal(t, `{"with": {"fruit": [{"type": "grapes", "count": 8}, {"type": "strawberries", "count": 3}]}}`)
+
+		nodes := MustCompile(`$.with.fruit[?@.count > 4]`).Query(jn)
+		require.Len(t, nodes, 1)
+		require.Equal(t, "/with/fruit/0", nodes[0].JSONPointer())
+
+		require.NoError(t, nodes[0].Set("count", float64(1)))
+
+		count, ok := jn.Pointer("/with/fruit/0/count").ValueAsFloat64()
+		require.True(t, ok)
+		require.Equal(t, float64(1), count)
+
+		// Relative to a child node
+		nodes = MustCompile(`$.fruit[*].type`).Query(jn.Get("with"))
+		require.Len(t, nodes, 2)
+		require.Equal(t, "/with/fruit/1/type", nodes[1].JSONPointer())
+
+		require.Nil(t, MustCompile(`$`).Query(nil))
+	})
+}
+
+func ExamplePath_Query() {
+	raw := `{
+    "platter": "slate",
+    "cheeses": ["cheddar", "swiss", "manchego"],
+    "with": {
+        "fruit": [{
+                "type": "grapes",
+                "count": 8
+            },
+            {
+                "type": "strawberries",
+                "count": 3
+            }
+        ],
+        "meat": "prosciutto"
+    }
+}`
+
+	jn := new(jsonnode.JSONNode)
+	err := json.Unmarshal([]byte(raw), jn)
+	if err != nil {
+		panic(err)
+	}
+
+	path := MustCompile(`$.with.fruit[?@.count > 4].type`)
+
+	for _, node := range path.Query(jn) {
+		fruitType, ok := node.ValueAsString()
+		if !ok {
+			panic(`"type" is not a string`)
+		}
+
+		fmt.Printf("%s: %s\n", node.JSONPointer(), fruitType)
+	}
+
+	// Output:
+	// /with/fruit/0/type: grapes
+}
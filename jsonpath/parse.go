@@ -0,0 +1,876 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The largest (and, negated, the smallest) integer allowed by RFC 9535 for indexes and slices.
+const maxInt = 1<<53 - 1
+
+// SyntaxError describes a problem with a JSONPath expression.
+type SyntaxError struct {
+	Expr   string // The JSONPath expression
+	Offset int    // The byte offset into Expr where the problem was found
+	Msg    string // What the problem is
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("jsonpath: %s at offset %d in %q", e.Msg, e.Offset, e.Expr)
+}
+
+type parser struct {
+	expr string
+	pos  int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{
+		Expr:   p.expr,
+		Offset: p.pos,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+func (p *parser) peek() byte {
+	if p.pos < len(p.expr) {
+		return p.expr[p.pos]
+	}
+
+	return 0
+}
+
+func (p *parser) consume(s string) bool {
+	if strings.HasPrefix(p.expr[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+
+	return false
+}
+
+// skipSpace skips over blank space, which is allowed in a lot of places (but not everywhere).
+func (p *parser) skipSpace() {
+	for p.pos < len(p.expr) {
+		switch p.expr[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+
+		default:
+			return
+		}
+	}
+}
+
+// unexpected describes whatever is at the current position, for error messages.
+func (p *parser) unexpected() error {
+	if p.pos >= len(p.expr) {
+		return p.errorf("unexpected end of expression")
+	}
+
+	r, _ := utf8.DecodeRuneInString(p.expr[p.pos:])
+
+	return p.errorf("unexpected %q", r)
+}
+
+// parseQuery parses a whole JSONPath query.
+func (p *parser) parseQuery() ([]segment, error) {
+	if !p.consume("$") {
+		return nil, p.errorf("query must start with '$'")
+	}
+
+	segments, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.expr) {
+		return nil, p.unexpected()
+	}
+
+	return segments, nil
+}
+
+// parseSegments parses zero or more segments, with optional blank space before each.
+func (p *parser) parseSegments() ([]segment, error) {
+	var segments []segment
+
+	for {
+		start := p.pos
+		p.skipSpace()
+
+		if c := p.peek(); c != '.' && c != '[' {
+			// Not a segment, so the blank space (if any) belongs to something else
+			p.pos = start
+			return segments, nil
+		}
+
+		seg, err := p.parseSegment()
+		if err != nil {
+			return nil, err
+		}
+
+		segments = append(segments, seg)
+	}
+}
+
+func (p *parser) parseSegment() (segment, error) {
+	seg := segment{}
+
+	switch {
+	case p.consume(".."):
+		seg.descendant = true
+
+		if p.peek() == '[' {
+			break
+		}
+
+		sel, err := p.parseShorthand()
+		if err != nil {
+			return seg, err
+		}
+
+		seg.selectors = []selector{sel}
+
+		return seg, nil
+
+	case p.consume("."):
+		sel, err := p.parseShorthand()
+		if err != nil {
+			return seg, err
+		}
+
+		seg.selectors = []selector{sel}
+
+		return seg, nil
+	}
+
+	selectors, err := p.parseBracketed()
+	if err != nil {
+		return seg, err
+	}
+
+	seg.selectors = selectors
+
+	return seg, nil
+}
+
+// parseShorthand parses the wildcard or member name that follows "." or "..".
+func (p *parser) parseShorthand() (selector, error) {
+	if p.consume("*") {
+		return wildcardSelector{}, nil
+	}
+
+	start := p.pos
+
+	for p.pos < len(p.expr) {
+		r, size := utf8.DecodeRuneInString(p.expr[p.pos:])
+		if !isNameChar(r, size, p.pos == start) {
+			break
+		}
+
+		p.pos += size
+	}
+
+	if p.pos == start {
+		return nil, p.errorf("expected a member name or '*'")
+	}
+
+	return nameSelector(p.expr[start:p.pos]), nil
+}
+
+func isNameChar(r rune, size int, first bool) bool {
+	switch {
+	case r == utf8.RuneError && size <= 1:
+		// Invalid UTF-8
+		return false
+
+	case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', r >= 0x80:
+		return true
+
+	case '0' <= r && r <= '9':
+		return !first
+	}
+
+	return false
+}
+
+func (p *parser) parseBracketed() ([]selector, error) {
+	if !p.consume("[") {
+		return nil, p.unexpected()
+	}
+
+	var selectors []selector
+
+	for {
+		p.skipSpace()
+
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+
+		selectors = append(selectors, sel)
+
+		p.skipSpace()
+
+		if p.consume(",") {
+			continue
+		}
+
+		if p.consume("]") {
+			return selectors, nil
+		}
+
+		return nil, p.unexpected()
+	}
+}
+
+func (p *parser) parseSelector() (selector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		name, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+
+		return nameSelector(name), nil
+
+	case c == '*':
+		p.pos++
+		return wildcardSelector{}, nil
+
+	case c == '?':
+		p.pos++
+		p.skipSpace()
+
+		start := p.pos
+
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if !isLogical(e) {
+			p.pos = start
+			return nil, p.errorf("filter must be a logical expression")
+		}
+
+		return &filterSelector{expr: e}, nil
+
+	case c == ':' || c == '-' || isDigit(c):
+		return p.parseIndexOrSlice()
+	}
+
+	return nil, p.unexpected()
+}
+
+func (p *parser) parseIndexOrSlice() (selector, error) {
+	var sel sliceSelector
+
+	if p.peek() != ':' {
+		start, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+
+		save := p.pos
+		p.skipSpace()
+
+		if p.peek() != ':' {
+			p.pos = save
+			return indexSelector(start), nil
+		}
+
+		sel.start, sel.hasStart = start, true
+	}
+
+	// Skip the ':'
+	p.pos++
+	p.skipSpace()
+
+	if c := p.peek(); c == '-' || isDigit(c) {
+		end, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+
+		sel.end, sel.hasEnd = end, true
+		p.skipSpace()
+	}
+
+	sel.step = 1
+
+	if p.consume(":") {
+		p.skipSpace()
+
+		if c := p.peek(); c == '-' || isDigit(c) {
+			step, err := p.parseInt()
+			if err != nil {
+				return nil, err
+			}
+
+			sel.step = step
+		}
+	}
+
+	return sel, nil
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// parseInt parses an integer as used by index and slice selectors.
+func (p *parser) parseInt() (int64, error) {
+	start := p.pos
+
+	p.consume("-")
+
+	if !isDigit(p.peek()) {
+		return 0, p.errorf("expected an integer")
+	}
+
+	if p.peek() == '0' {
+		p.pos++
+
+		if isDigit(p.peek()) {
+			return 0, p.errorf("leading zeros are not allowed")
+		}
+
+		if p.pos-start > 1 {
+			p.pos = start
+			return 0, p.errorf("-0 is not allowed")
+		}
+
+		return 0, nil
+	}
+
+	for isDigit(p.peek()) {
+		p.pos++
+	}
+
+	i, err := strconv.ParseInt(p.expr[start:p.pos], 10, 64)
+	if err != nil || i > maxInt || i < -maxInt {
+		p.pos = start
+		return 0, p.errorf("integer out of range")
+	}
+
+	return i, nil
+}
+
+// parseString parses a single- or double-quoted string literal.
+func (p *parser) parseString() (string, error) {
+	quote := p.expr[p.pos]
+	p.pos++
+
+	var sb strings.Builder
+
+	for {
+		if p.pos >= len(p.expr) {
+			return "", p.errorf("unterminated string")
+		}
+
+		c := p.expr[p.pos]
+
+		switch {
+		case c == quote:
+			p.pos++
+			return sb.String(), nil
+
+		case c == '\\':
+			p.pos++
+
+			r, err := p.parseEscape(quote)
+			if err != nil {
+				return "", err
+			}
+
+			sb.WriteRune(r)
+
+		case c < 0x20:
+			return "", p.errorf("control characters must be escaped in strings")
+
+		default:
+			r, size := utf8.DecodeRuneInString(p.expr[p.pos:])
+			if r == utf8.RuneError && size <= 1 {
+				return "", p.errorf("invalid UTF-8")
+			}
+
+			sb.WriteString(p.expr[p.pos : p.pos+size])
+			p.pos += size
+		}
+	}
+}
+
+// parseEscape parses the escape sequence following a '\' in a string literal.
+func (p *parser) parseEscape(quote byte) (rune, error) {
+	c := p.peek()
+	p.pos++
+
+	switch c {
+	case 'b':
+		return '\b', nil
+
+	case 'f':
+		return '\f', nil
+
+	case 'n':
+		return '\n', nil
+
+	case 'r':
+		return '\r', nil
+
+	case 't':
+		return '\t', nil
+
+	case '/', '\\':
+		return rune(c), nil
+
+	case quote:
+		return rune(quote), nil
+
+	case 'u':
+		r, err := p.parseHex4()
+		if err != nil {
+			return 0, err
+		}
+
+		switch {
+		case 0xDC00 <= r && r <= 0xDFFF:
+			return 0, p.errorf("unpaired low surrogate")
+
+		case 0xD800 <= r && r <= 0xDBFF:
+			if !p.consume(`\u`) {
+				return 0, p.errorf("unpaired high surrogate")
+			}
+
+			low, err := p.parseHex4()
+			if err != nil {
+				return 0, err
+			}
+
+			if low < 0xDC00 || low > 0xDFFF {
+				return 0, p.errorf("invalid low surrogate")
+			}
+
+			return 0x10000 + (r-0xD800)<<10 + (low - 0xDC00), nil
+		}
+
+		return r, nil
+	}
+
+	p.pos--
+
+	return 0, p.errorf("invalid escape sequence")
+}
+
+func (p *parser) parseHex4() (rune, error) {
+	if p.pos+4 > len(p.expr) {
+		return 0, p.errorf("invalid unicode escape")
+	}
+
+	r, err := strconv.ParseUint(p.expr[p.pos:p.pos+4], 16, 32)
+	if err != nil {
+		return 0, p.errorf("invalid unicode escape")
+	}
+
+	p.pos += 4
+
+	return rune(r), nil
+}
+
+// parseOr parses a logical OR expression (or anything with higher precedence).
+func (p *parser) parseOr() (expr, error) {
+	return p.parseLogical("||", p.parseAnd, func(operands []expr) expr { return orExpr(operands) })
+}
+
+// parseAnd parses a logical AND expression (or anything with higher precedence).
+func (p *parser) parseAnd() (expr, error) {
+	return p.parseLogical("&&", p.parseComparison, func(operands []expr) expr { return andExpr(operands) })
+}
+
+func (p *parser) parseLogical(op string, parseOperand func() (expr, error), build func([]expr) expr) (expr, error) {
+	start := p.pos
+
+	first, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	operands := []expr{first}
+
+	for {
+		save := p.pos
+		p.skipSpace()
+
+		if !p.consume(op) {
+			p.pos = save
+			break
+		}
+
+		p.skipSpace()
+
+		next, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		operands = append(operands, next)
+	}
+
+	if len(operands) == 1 {
+		return first, nil
+	}
+
+	for _, operand := range operands {
+		if !isLogical(operand) {
+			p.pos = start
+			return nil, p.errorf("operands of %s must be logical expressions", op)
+		}
+	}
+
+	return build(operands), nil
+}
+
+var comparisonOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func (p *parser) parseComparison() (expr, error) {
+	start := p.pos
+
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	save := p.pos
+	p.skipSpace()
+
+	op := ""
+	for _, candidate := range comparisonOps {
+		if p.consume(candidate) {
+			op = candidate
+			break
+		}
+	}
+
+	if op == "" {
+		p.pos = save
+		return left, nil
+	}
+
+	p.skipSpace()
+
+	right, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	if !isComparable(left) || !isComparable(right) {
+		p.pos = start
+		return nil, p.errorf("operands of %s must be literals, singular queries, or functions returning a value", op)
+	}
+
+	return &comparisonExpr{op: op, left: left, right: right}, nil
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	if !p.consume("!") {
+		return p.parsePrimary()
+	}
+
+	p.skipSpace()
+	start := p.pos
+
+	operand, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	switch e := operand.(type) {
+	case *parenExpr, *queryExpr:
+		return notExpr{operand}, nil
+
+	case *funcExpr:
+		if e.fn.result != valueType {
+			return notExpr{operand}, nil
+		}
+	}
+
+	p.pos = start
+
+	return nil, p.errorf("! can only be applied to parenthesized expressions, queries, and functions returning a logical value or nodes")
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	start := p.pos
+
+	switch c := p.peek(); {
+	case c == '(':
+		p.pos++
+		p.skipSpace()
+
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipSpace()
+
+		if !p.consume(")") {
+			return nil, p.unexpected()
+		}
+
+		if !isLogical(inner) {
+			p.pos = start
+			return nil, p.errorf("parentheses must contain a logical expression")
+		}
+
+		return &parenExpr{inner}, nil
+
+	case c == '@' || c == '$':
+		p.pos++
+
+		segments, err := p.parseSegments()
+		if err != nil {
+			return nil, err
+		}
+
+		return &queryExpr{
+			absolute: c == '$',
+			segments: segments,
+			singular: isSingular(segments),
+		}, nil
+
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+
+		return literalExpr{s}, nil
+
+	case c == '-' || isDigit(c):
+		return p.parseNumber()
+
+	case 'a' <= c && c <= 'z':
+		for p.pos < len(p.expr) {
+			c := p.expr[p.pos]
+			if c != '_' && !isDigit(c) && (c < 'a' || c > 'z') {
+				break
+			}
+
+			p.pos++
+		}
+
+		name := p.expr[start:p.pos]
+
+		if p.peek() == '(' {
+			return p.parseFunction(name, start)
+		}
+
+		switch name {
+		case "true":
+			return literalExpr{true}, nil
+
+		case "false":
+			return literalExpr{false}, nil
+
+		case "null":
+			return literalExpr{nil}, nil
+		}
+
+		p.pos = start
+	}
+
+	return nil, p.unexpected()
+}
+
+func (p *parser) parseNumber() (expr, error) {
+	start := p.pos
+
+	p.consume("-")
+
+	if !isDigit(p.peek()) {
+		return nil, p.errorf("expected a number")
+	}
+
+	if p.peek() == '0' {
+		p.pos++
+
+		if isDigit(p.peek()) {
+			return nil, p.errorf("leading zeros are not allowed")
+		}
+	}
+
+	for isDigit(p.peek()) {
+		p.pos++
+	}
+
+	if p.consume(".") {
+		if !isDigit(p.peek()) {
+			return nil, p.errorf("expected a digit")
+		}
+
+		for isDigit(p.peek()) {
+			p.pos++
+		}
+	}
+
+	if c := p.peek(); c == 'e' || c == 'E' {
+		p.pos++
+
+		if c := p.peek(); c == '-' || c == '+' {
+			p.pos++
+		}
+
+		if !isDigit(p.peek()) {
+			return nil, p.errorf("expected a digit")
+		}
+
+		for isDigit(p.peek()) {
+			p.pos++
+		}
+	}
+
+	f, err := strconv.ParseFloat(p.expr[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("number out of range")
+	}
+
+	return literalExpr{f}, nil
+}
+
+func (p *parser) parseFunction(name string, start int) (expr, error) {
+	fn, ok := functions[name]
+	if !ok {
+		p.pos = start
+		return nil, p.errorf("unknown function %q", name)
+	}
+
+	// Skip the '('
+	p.pos++
+	p.skipSpace()
+
+	var args []expr
+
+	if !p.consume(")") {
+		for {
+			argStart := p.pos
+
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+
+			if len(args) < len(fn.params) && !fitsParam(arg, fn.params[len(args)]) {
+				p.pos = argStart
+				return nil, p.errorf("argument %d of %s() must be %s", len(args)+1, name, fn.params[len(args)])
+			}
+
+			args = append(args, arg)
+
+			p.skipSpace()
+
+			if p.consume(",") {
+				p.skipSpace()
+				continue
+			}
+
+			if p.consume(")") {
+				break
+			}
+
+			return nil, p.unexpected()
+		}
+	}
+
+	if len(args) != len(fn.params) {
+		p.pos = start
+		return nil, p.errorf("%s() takes %d argument(s), not %d", name, len(fn.params), len(args))
+	}
+
+	if name == "match" || name == "search" {
+		// Compile a literal pattern once, rather than every time the function is called
+		if lit, ok := args[1].(literalExpr); ok {
+			if pattern, ok := lit.value.(string); ok {
+				args[1] = literalExpr{compileIRegexp(pattern, name == "match")}
+			}
+		}
+	}
+
+	return &funcExpr{name: name, fn: fn, args: args}, nil
+}
+
+// isSingular reports whether the segments can only ever select at most one node.
+func isSingular(segments []segment) bool {
+	for _, seg := range segments {
+		if seg.descendant || len(seg.selectors) != 1 {
+			return false
+		}
+
+		switch seg.selectors[0].(type) {
+		case nameSelector, indexSelector:
+
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// isLogical reports whether e can be used where a logical value is expected.
+// Queries and functions returning nodes are converted to whether they selected any nodes.
+func isLogical(e expr) bool {
+	switch e := e.(type) {
+	case *comparisonExpr, andExpr, orExpr, notExpr, *parenExpr, *queryExpr:
+		return true
+
+	case *funcExpr:
+		return e.fn.result != valueType
+	}
+
+	return false
+}
+
+// isComparable reports whether e can be used as an operand of a comparison (or where a value is expected).
+func isComparable(e expr) bool {
+	switch e := e.(type) {
+	case literalExpr:
+		return true
+
+	case *queryExpr:
+		return e.singular
+
+	case *funcExpr:
+		return e.fn.result == valueType
+	}
+
+	return false
+}
+
+// fitsParam reports whether e can be passed to a function parameter of type t.
+func fitsParam(e expr, t paramType) bool {
+	switch t {
+	case valueType:
+		return isComparable(e)
+
+	case logicalType:
+		return isLogical(e)
+
+	case nodesType:
+		switch e := e.(type) {
+		case *queryExpr:
+			return true
+
+		case *funcExpr:
+			return e.fn.result == nodesType
+		}
+	}
+
+	return false
+}
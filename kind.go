@@ -0,0 +1,136 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Kind is the type of JSON value a node holds.
+type Kind int
+
+// The kinds of JSON values. KindMissing is the Kind of a node that doesn't exist, such as the nil
+// *JSONNode returned by Get for a field that is not there.
+const (
+	KindMissing Kind = iota
+	KindNull
+	KindBool
+	KindNumber
+	KindString
+	KindArray
+	KindObject
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindMissing:
+		return "missing"
+
+	case KindNull:
+		return "null"
+
+	case KindBool:
+		return "bool"
+
+	case KindNumber:
+		return "number"
+
+	case KindString:
+		return "string"
+
+	case KindArray:
+		return "array"
+
+	case KindObject:
+		return "object"
+	}
+
+	return "unknown"
+}
+
+// kindOf gets the Kind of a value.
+func kindOf(value interface{}) Kind {
+	switch value.(type) {
+	case nil:
+		return KindNull
+
+	case bool:
+		return KindBool
+
+	case float64, json.Number:
+		return KindNumber
+
+	case string:
+		return KindString
+
+	case []interface{}:
+		return KindArray
+
+	case *Object:
+		return KindObject
+	}
+
+	// Not one of the types used to represent JSON, so go by what it would marshal as
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Bool:
+		return KindBool
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return KindNumber
+
+	case reflect.String:
+		return KindString
+
+	case reflect.Slice, reflect.Array:
+		return KindArray
+	}
+
+	return KindObject
+}
+
+// Kind gets the type of JSON value this node holds.
+// KindMissing is returned if this node is nil, or no longer exists because its parent was modified.
+func (jn *JSONNode) Kind() Kind {
+	value, ok := jn.lookupShallow()
+	if !ok {
+		return KindMissing
+	}
+
+	return kindOf(value)
+}
+
+// Exists reports whether this node exists. A field holding null exists; a nil *JSONNode does not.
+func (jn *JSONNode) Exists() bool {
+	return jn.Kind() != KindMissing
+}
+
+// IsNull reports whether this node holds a JSON null.
+// This is false for a node that does not exist.
+func (jn *JSONNode) IsNull() bool {
+	return jn.Kind() == KindNull
+}
+
+// IsObject reports whether this node holds a JSON object.
+func (jn *JSONNode) IsObject() bool {
+	return jn.Kind() == KindObject
+}
+
+// IsArray reports whether this node holds a JSON array.
+func (jn *JSONNode) IsArray() bool {
+	return jn.Kind() == KindArray
+}
+
+// Len gets the number of members of a JSON object, or the number of elements of a JSON array.
+// 0 is returned for anything else.
+func (jn *JSONNode) Len() int {
+	switch value := jn.shallowValue().(type) {
+	case *Object:
+		return value.Len()
+
+	case []interface{}:
+		return len(value)
+	}
+
+	return 0
+}
@@ -0,0 +1,86 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONNodeKind(t *testing.T) {
+	t.Parallel()
+
+	jn := new(JSONNode)
+	err := json.Unmarshal([]byte(`{"n":null,"b":false,"num":1.5,"s":"x","a":[1,2,3],"o":{"k":"v"}}`), jn)
+	require.NoError(t, err)
+
+	t.Run("kinds", func(t *testing.T) {
+		t.Parallel()
+
+		for field, expected := range map[string]Kind{
+			"n":       KindNull,
+			"b":       KindBool,
+			"num":     KindNumber,
+			"s":       KindString,
+			"a":       KindArray,
+			"o":       KindObject,
+			"missing": KindMissing,
+		} {
+			require.Equal(t, expected, jn.Get(field).Kind(), field)
+		}
+
+		require.Equal(t, KindObject, jn.Kind())
+		require.Equal(t, KindNumber, jn.Get("a").Index(1).Kind())
+		require.Equal(t, KindMissing, jn.Get("a").Index(3).Kind())
+		require.Equal(t, "object", KindObject.String())
+		require.Equal(t, "missing", KindMissing.String())
+		require.Equal(t, KindNull, NewFromValue(nil).Kind())
+	})
+
+	t.Run("null or missing", func(t *testing.T) {
+		t.Parallel()
+
+		require.True(t, jn.Get("n").Exists())
+		require.True(t, jn.Get("n").IsNull())
+
+		require.False(t, jn.Get("missing").Exists())
+		require.False(t, jn.Get("missing").IsNull())
+
+		require.True(t, jn.Get("o").IsObject())
+		require.False(t, jn.Get("o").IsArray())
+		require.True(t, jn.Get("a").IsArray())
+	})
+
+	t.Run("len", func(t *testing.T) {
+		t.Parallel()
+
+		require.Equal(t, 6, jn.Len())
+		require.Equal(t, 3, jn.Get("a").Len())
+		require.Equal(t, 1, jn.Get("o").Len())
+		require.Equal(t, 0, jn.Get("s").Len())
+		require.Equal(t, 0, jn.Get("missing").Len())
+	})
+
+	t.Run("bool", func(t *testing.T) {
+		t.Parallel()
+
+		b, ok := jn.Get("b").ValueAsBool()
+		require.True(t, ok)
+		require.False(t, b)
+
+		_, ok = jn.Get("s").ValueAsBool()
+		require.False(t, ok)
+	})
+
+	t.Run("stale child", func(t *testing.T) {
+		t.Parallel()
+
+		doc := NewFromValue(map[string]interface{}{"a": map[string]interface{}{"b": nil}})
+		child := doc.Get("a").Get("b")
+		require.True(t, child.IsNull())
+
+		require.NoError(t, doc.Set("a", "replaced"))
+		require.False(t, child.Exists())
+		require.False(t, child.IsNull())
+	})
+}
@@ -0,0 +1,225 @@
+package jsonnode
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"unicode/utf8"
+)
+
+var _ json.Marshaler = (*rawValue)(nil)
+
+// document is shared by all of the nodes of a document.
+type document struct {
+	// gen is incremented whenever something in the document is changed (see parentValue).
+	gen uint64
+
+	// lazy is set while there may still be values in the document that haven't been decoded.
+	lazy bool
+}
+
+// rawValue is a value in a lazily decoded document that hasn't been decoded yet.
+// data has already been checked to be valid JSON.
+type rawValue struct {
+	data      []byte
+	useNumber bool
+}
+
+// MarshalJSON returns the JSON as it was.
+func (r *rawValue) MarshalJSON() ([]byte, error) {
+	return r.data, nil
+}
+
+// decodeShallow decodes the value. The members of an object or the elements of an array are left
+// to be decoded later.
+func (r *rawValue) decodeShallow() interface{} {
+	data := r.data
+	i := skipSpace(data, 1)
+
+	switch data[0] {
+	case '{':
+		obj := NewObject()
+
+		for data[i] != '}' {
+			end := skipString(data, i)
+			key := decodeScalar(data[i:end], false).(string)
+
+			// Skip the ':'
+			i = skipSpace(data, skipSpace(data, end)+1)
+
+			end = skipValue(data, i)
+			obj.set(key, &rawValue{data: data[i:end], useNumber: r.useNumber})
+
+			i = skipSeparator(data, end)
+		}
+
+		return obj
+
+	case '[':
+		arr := make([]interface{}, 0)
+
+		for data[i] != ']' {
+			end := skipValue(data, i)
+			arr = append(arr, &rawValue{data: data[i:end], useNumber: r.useNumber})
+
+			i = skipSeparator(data, end)
+		}
+
+		return arr
+	}
+
+	return decodeScalar(data, r.useNumber)
+}
+
+// expand decodes value if it is a *rawValue, leaving anything under it to be decoded later.
+// It reports whether value was decoded, so the caller can put the decoded value in its place.
+func expand(value interface{}) (interface{}, bool) {
+	raw, ok := value.(*rawValue)
+	if !ok {
+		return value, false
+	}
+
+	return raw.decodeShallow(), true
+}
+
+// materialize decodes anything in value that hasn't been decoded yet, in place.
+// The decoded value is returned.
+func materialize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *rawValue:
+		return materialize(v.decodeShallow())
+
+	case *Object:
+		for key, member := range v.values {
+			v.values[key] = materialize(member)
+		}
+
+	case []interface{}:
+		for i := range v {
+			v[i] = materialize(v[i])
+		}
+	}
+
+	return value
+}
+
+// decodeScalar decodes a JSON string, number, bool, or null.
+func decodeScalar(data []byte, useNumber bool) interface{} {
+	switch data[0] {
+	case '"':
+		if bytes.IndexByte(data, '\\') < 0 && utf8.Valid(data) {
+			return string(data[1 : len(data)-1])
+		}
+
+		var s string
+		_ = json.Unmarshal(data, &s)
+
+		return s
+
+	case 't':
+		return true
+
+	case 'f':
+		return false
+
+	case 'n':
+		return nil
+	}
+
+	if useNumber {
+		return json.Number(data)
+	}
+
+	f, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		// Too large for a float64
+		return json.Number(data)
+	}
+
+	return f
+}
+
+// skipSpace gets the index of the first byte at or after i that isn't whitespace.
+func skipSpace(data []byte, i int) int {
+	for i < len(data) {
+		switch data[i] {
+		case ' ', '\t', '\r', '\n':
+			i++
+
+		default:
+			return i
+		}
+	}
+
+	return i
+}
+
+// skipSeparator skips past any whitespace and comma after the value that ended at i, returning the
+// index of the next value or the closing '}' or ']'.
+func skipSeparator(data []byte, i int) int {
+	i = skipSpace(data, i)
+	if data[i] == ',' {
+		i = skipSpace(data, i+1)
+	}
+
+	return i
+}
+
+// skipString gets the index just after the JSON string starting at i.
+func skipString(data []byte, i int) int {
+	for i++; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+
+		case '"':
+			return i + 1
+		}
+	}
+
+	return i
+}
+
+// skipValue gets the index just after the JSON value starting at i.
+func skipValue(data []byte, i int) int {
+	switch data[i] {
+	case '"':
+		return skipString(data, i)
+
+	case '{', '[':
+		depth := 0
+
+		for i < len(data) {
+			switch data[i] {
+			case '"':
+				i = skipString(data, i)
+				continue
+
+			case '{', '[':
+				depth++
+
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+
+			i++
+		}
+
+		return i
+	}
+
+	// A number, bool, or null
+	for i < len(data) {
+		switch data[i] {
+		case ',', '}', ']', ' ', '\t', '\r', '\n':
+			return i
+		}
+
+		i++
+	}
+
+	return i
+}
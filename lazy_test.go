@@ -0,0 +1,297 @@
+package jsonnode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalLazy(t *testing.T) {
+	t.Parallel()
+
+	lazy := func(t *testing.T, data string) *JSONNode {
+		jn, err := Unmarshal([]byte(data), DecoderOptions{Lazy: true})
+		require.NoError(t, err)
+
+		return jn
+	}
+
+	// raw reports whether the value at ptr hasn't been decoded yet, without decoding it
+	raw := func(jn *JSONNode, ptr string) bool {
+		tokens, _ := parsePointer(ptr)
+		value := jn.data
+
+		for _, token := range tokens {
+			switch v := value.(type) {
+			case *Object:
+				value = v.values[token]
+
+			case []interface{}:
+				i, _ := parseArrayIndex(token)
+				value = v[i]
+
+			default:
+				return false
+			}
+		}
+
+		_, ok := value.(*rawValue)
+
+		return ok
+	}
+
+	t.Run("same as eager", func(t *testing.T) {
+		t.Parallel()
+
+		for _, data := range []string{
+			platterJSON,
+			` {"a": {"b": [1, {"c": "d\"}"}, []]}, "eé": "😀 \\ \"", "f": [true, false, null]} `,
+			`[[], {}, [[[]]], -1.5e3, "]"]`,
+			`"just a string"`,
+			`12.5`,
+			`null`,
+			`{"dup": 1, "dup": 2}`,
+			"[\"bad \xff utf-8\"]",
+		} {
+			for _, useNumber := range []bool{false, true} {
+				eager, err := Unmarshal([]byte(data), DecoderOptions{UseNumber: useNumber})
+				require.NoError(t, err)
+
+				jn, err := Unmarshal([]byte(data), DecoderOptions{UseNumber: useNumber, Lazy: true})
+				require.NoError(t, err)
+
+				require.True(t, EqualWithOptions(eager, jn, EqualOptions{}), data)
+				require.Equal(t, marshal(t, eager), marshal(t, jn), data)
+				require.Equal(t, eager.Value(), jn.Value(), data)
+			}
+		}
+	})
+
+	t.Run("decodes what is used", func(t *testing.T) {
+		t.Parallel()
+
+		jn := lazy(t, platterJSON)
+		require.True(t, raw(jn, ""))
+
+		require.Equal(t, []string{"platter", "cheeses", "with"}, jn.Keys())
+		require.False(t, raw(jn, ""))
+		require.True(t, raw(jn, "/cheeses"))
+		require.True(t, raw(jn, "/with"))
+
+		count, err := jn.Int64("with", "fruit", 1, "count")
+		require.NoError(t, err)
+		require.Equal(t, int64(3), count)
+		require.True(t, raw(jn, "/cheeses"))
+		require.True(t, raw(jn, "/with/fruit/0"))
+		require.False(t, raw(jn, "/with/fruit/1"))
+
+		require.Equal(t, KindArray, jn.Get("cheeses").Kind())
+		require.Equal(t, 3, jn.Get("cheeses").Len())
+		require.False(t, raw(jn, "/cheeses"))
+
+		with := jn.Get("with").OrderedValue()
+		require.False(t, raw(jn, "/with/fruit/0"))
+		require.Equal(t, "grapes", with.(*Object).values["fruit"].([]interface{})[0].(*Object).values["type"])
+
+		require.True(t, jn.doc.lazy)
+		jn.Value()
+		require.False(t, jn.doc.lazy)
+	})
+
+	t.Run("marshal", func(t *testing.T) {
+		t.Parallel()
+
+		data := `{"id": "x", "body": {"amount": 1.10, "big": 12345678901234567890, "tags": ["a",  "b"]}}`
+
+		jn := lazy(t, data)
+		require.NoError(t, jn.Set("id", "y"))
+		require.Equal(t, `{"id":"y","body":{"amount":1.10,"big":12345678901234567890,"tags":["a","b"]}}`, marshal(t, jn))
+		require.True(t, raw(jn, "/body"))
+
+		body, err := jn.Get("body").MarshalJSON()
+		require.NoError(t, err)
+		require.Equal(t, `{"amount":1.10,"big":12345678901234567890,"tags":["a","b"]}`, string(body))
+		require.True(t, raw(jn, "/body"))
+
+		// Reading numbers doesn't change how they're written
+		amount, ok := jn.Pointer("/body/amount").ValueAsFloat64()
+		require.True(t, ok)
+		require.Equal(t, 1.1, amount)
+
+		require.Equal(t, 12345678901234567890.0, jn.Pointer("/body/big").Value())
+
+		require.Equal(t, KindString, jn.Pointer("/body/tags").Index(0).Kind())
+		require.True(t, raw(jn, "/body/amount"))
+		require.True(t, raw(jn, "/body/big"))
+		require.Equal(t, `{"id":"y","body":{"amount":1.10,"big":12345678901234567890,"tags":["a","b"]}}`, marshal(t, jn))
+
+		require.NoError(t, jn.Pointer("/body/tags").Append("c"))
+		require.Equal(t, `{"id":"y","body":{"amount":1.10,"big":12345678901234567890,"tags":["a","b","c"]}}`, marshal(t, jn))
+
+		// Decoding the numbers changes how they're written, like it does without Lazy
+		jn.Value()
+		require.Equal(t, `{"id":"y","body":{"amount":1.1,"big":12345678901234567000,"tags":["a","b","c"]}}`, marshal(t, jn))
+	})
+
+	t.Run("mutate", func(t *testing.T) {
+		t.Parallel()
+
+		jn := lazy(t, platterJSON)
+		require.NoError(t, jn.SetPointer("/with/fruit/0/count", 9))
+		require.NoError(t, jn.DeletePointer("/cheeses/1"))
+		require.NoError(t, jn.Pointer("/with/fruit").RemoveAt(1))
+		require.NoError(t, jn.Pointer("/with").Set("bread", []interface{}{"rye"}))
+
+		require.JSONEq(t, `{
+			"platter": "slate",
+			"cheeses": ["cheddar", "manchego"],
+			"with": {"fruit": [{"type": "grapes", "count": 9}], "meat": "prosciutto", "bread": ["rye"]}
+		}`, marshal(t, jn))
+
+		other := New()
+		require.NoError(t, other.Set("copy", lazy(t, platterJSON).Get("with")))
+		require.False(t, other.doc.lazy)
+		require.False(t, raw(other, "/copy/fruit"))
+
+		clone := lazy(t, platterJSON).Get("with").Clone()
+		require.False(t, raw(clone, "/fruit"))
+	})
+
+	t.Run("numbers", func(t *testing.T) {
+		t.Parallel()
+
+		jn := lazy(t, `[1e400, 1.5]`)
+		require.Equal(t, []interface{}{json.Number("1e400"), 1.5}, jn.Value())
+
+		jn, err := Unmarshal([]byte(`[1e400, 1.5]`), DecoderOptions{Lazy: true, UseNumber: true})
+		require.NoError(t, err)
+		require.Equal(t, []interface{}{json.Number("1e400"), json.Number("1.5")}, jn.Value())
+	})
+
+	t.Run("decode", func(t *testing.T) {
+		t.Parallel()
+
+		jn := lazy(t, `{"count": 1.0, "with": {"fruit": [{"count": 8}]}}`)
+
+		var counts struct {
+			Count int `json:"count"`
+		}
+
+		// The same as json.Unmarshal, which keeps the literal
+		err := jn.Decode(&counts)
+		require.Error(t, err)
+
+		var with struct {
+			With *JSONNode `json:"with"`
+		}
+
+		require.NoError(t, jn.Decode(&with))
+		require.Equal(t, 8.0, with.With.Get("fruit").Index(0).Get("count").Value())
+		require.False(t, with.With.doc.lazy)
+		require.True(t, raw(jn, ""))
+	})
+
+	t.Run("concurrent reads once decoded", func(t *testing.T) {
+		t.Parallel()
+
+		for i := 0; i < 20; i++ {
+			jn := lazy(t, platterJSON)
+			jn.Value()
+
+			start := make(chan struct{})
+
+			var wg sync.WaitGroup
+			for j := 0; j < 4; j++ {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+					<-start
+
+					if v := jn.Get("with").Get("fruit").Index(1).Get("count").Value(); v != 3.0 {
+						t.Errorf("expected 3, got %v", v)
+					}
+
+					if _, err := json.Marshal(jn); err != nil {
+						t.Error(err)
+					}
+				}()
+			}
+
+			close(start)
+			wg.Wait()
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		for _, data := range []string{``, `{`, `{"a": }`, `[1,]`, `"abc`, `[1] [2]`, `{"a" 1}`} {
+			_, eagerErr := Unmarshal([]byte(data), DecoderOptions{})
+			require.Error(t, eagerErr, data)
+
+			jn, err := Unmarshal([]byte(data), DecoderOptions{Lazy: true})
+			require.Nil(t, jn, data)
+			require.Equal(t, eagerErr, err, data)
+		}
+	})
+
+	t.Run("walk", func(t *testing.T) {
+		t.Parallel()
+
+		jn := lazy(t, platterJSON)
+
+		var paths []string
+		err := jn.Walk(func(path Path, n *JSONNode) error {
+			paths = append(paths, path.String())
+			if len(path) == 1 {
+				return SkipChildren
+			}
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"", "/platter", "/cheeses", "/with"}, paths)
+		require.True(t, raw(jn, "/with"))
+	})
+}
+
+func BenchmarkUnmarshalLazy(b *testing.B) {
+	var buf bytes.Buffer
+
+	buf.WriteString(`{"items": [`)
+	for i := 0; i < 10000; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		fmt.Fprintf(&buf, `{"id": %d, "name": "item %[1]d", "tags": ["a", "b", "c"], "price": %[1]d.99}`, i)
+	}
+	buf.WriteString(`], "event": "order.created"}`)
+
+	data := buf.Bytes()
+
+	for _, lazy := range []bool{false, true} {
+		b.Run(fmt.Sprintf("lazy=%v", lazy), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+
+			for i := 0; i < b.N; i++ {
+				jn, err := Unmarshal(data, DecoderOptions{Lazy: lazy})
+				require.NoError(b, err)
+
+				event, err := jn.String("event")
+				require.NoError(b, err)
+
+				if !strings.HasPrefix(event, "order.") {
+					b.Fatal(event)
+				}
+			}
+		})
+	}
+}
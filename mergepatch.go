@@ -0,0 +1,131 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrMergeNull is returned by CreateMergePatch when the modified document has a JSON object member
+// set to null. JSON Merge Patch uses null to mean "remove this member", so this can't be represented.
+var ErrMergeNull = errors.New("jsonnode: a JSON Merge Patch cannot set an object member to null")
+
+// MergePatch applies a JSON Merge Patch (RFC 7386) to this node.
+// patch can be a JSON Merge Patch document as a []byte, or a *JSONNode holding one.
+// Members of the patch that are null are removed from this node.
+func (jn *JSONNode) MergePatch(patch interface{}) error {
+	if jn == nil {
+		return ErrNilNode
+	}
+
+	var patchVal interface{}
+	var err error
+
+	switch p := patch.(type) {
+	case []byte:
+		patchVal, err = decode(p, DecoderOptions{})
+		if err != nil {
+			return err
+		}
+
+	case json.RawMessage:
+		patchVal, err = decode(p, DecoderOptions{})
+		if err != nil {
+			return err
+		}
+
+	case *JSONNode:
+		patchVal = p.OrderedValue()
+
+	default:
+		return fmt.Errorf("jsonnode: cannot use %T as a JSON Merge Patch", patch)
+	}
+
+	return jn.setValue(mergeValue(jn.OrderedValue(), patchVal))
+}
+
+// mergeValue merges patch into target, returning the result. Objects in target are modified in place.
+func mergeValue(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(*Object)
+	if !ok {
+		return copyValue(patch)
+	}
+
+	targetObj, ok := target.(*Object)
+	if !ok {
+		targetObj = NewObject()
+	}
+
+	for _, key := range patchObj.keys {
+		value := patchObj.values[key]
+
+		if value == nil {
+			targetObj.Delete(key)
+			continue
+		}
+
+		targetVal, _ := targetObj.Get(key)
+		targetObj.Set(key, mergeValue(targetVal, value))
+	}
+
+	return targetObj
+}
+
+// CreateMergePatch generates a JSON Merge Patch (RFC 7386) that turns original into modified.
+// ErrMergeNull is returned if that can't be done because modified has an object member set to null.
+func CreateMergePatch(original, modified *JSONNode) (*JSONNode, error) {
+	if original == nil || modified == nil {
+		return nil, ErrNilNode
+	}
+
+	patch, err := createMerge(original.OrderedValue(), modified.OrderedValue())
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFromValue(patch), nil
+}
+
+func createMerge(original, modified interface{}) (interface{}, error) {
+	modifiedObj, ok := modified.(*Object)
+	if !ok {
+		// Anything other than an object replaces the original entirely
+		return copyValue(modified), nil
+	}
+
+	originalObj, ok := original.(*Object)
+	if !ok {
+		// Merging an object into something that isn't an object starts from an empty object
+		originalObj = NewObject()
+	}
+
+	patch := NewObject()
+
+	for _, key := range originalObj.keys {
+		if _, ok := modifiedObj.Get(key); !ok {
+			patch.set(key, nil)
+		}
+	}
+
+	for _, key := range modifiedObj.keys {
+		modifiedVal := modifiedObj.values[key]
+
+		originalVal, ok := originalObj.Get(key)
+		if ok && equalValues(originalVal, modifiedVal) {
+			continue
+		}
+
+		if modifiedVal == nil {
+			return nil, ErrMergeNull
+		}
+
+		value, err := createMerge(originalVal, modifiedVal)
+		if err != nil {
+			return nil, err
+		}
+
+		patch.set(key, value)
+	}
+
+	return patch, nil
+}
@@ -0,0 +1,134 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONNodeMergePatch(t *testing.T) {
+	t.Parallel()
+
+	// The examples from RFC 7386, appendix A
+	examples := []struct {
+		original, patch, result string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	t.Run("rfc examples", func(t *testing.T) {
+		t.Parallel()
+
+		for _, example := range examples {
+			jn := new(JSONNode)
+			err := json.Unmarshal([]byte(example.original), jn)
+			require.NoError(t, err)
+
+			err = jn.MergePatch([]byte(example.patch))
+			require.NoError(t, err, example.patch)
+
+			data, err := json.Marshal(jn)
+			require.NoError(t, err)
+			require.JSONEq(t, example.result, string(data), "%s + %s", example.original, example.patch)
+		}
+	})
+
+	t.Run("child node", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+
+		patch := new(JSONNode)
+		err := json.Unmarshal([]byte(`{"meat": null, "bread": {"type": "rye"}}`), patch)
+		require.NoError(t, err)
+
+		err = jn.Get("with").MergePatch(patch)
+		require.NoError(t, err)
+
+		require.Nil(t, jn.Pointer("/with/meat"))
+
+		bread, ok := jn.Pointer("/with/bread/type").ValueAsString()
+		require.True(t, ok)
+		require.Equal(t, "rye", bread)
+
+		// The patch isn't tied to the document
+		require.NoError(t, patch.Get("bread").Set("type", "sourdough"))
+
+		bread, ok = jn.Pointer("/with/bread/type").ValueAsString()
+		require.True(t, ok)
+		require.Equal(t, "rye", bread)
+
+		require.Error(t, jn.MergePatch("not a patch"))
+		require.Error(t, jn.MergePatch([]byte("{")))
+	})
+
+	t.Run("create", func(t *testing.T) {
+		t.Parallel()
+
+		for _, example := range examples {
+			original := new(JSONNode)
+			err := json.Unmarshal([]byte(example.original), original)
+			require.NoError(t, err)
+
+			modified := new(JSONNode)
+			err = json.Unmarshal([]byte(example.result), modified)
+			require.NoError(t, err)
+
+			patch, err := CreateMergePatch(original, modified)
+			require.NoError(t, err)
+
+			err = original.MergePatch(patch)
+			require.NoError(t, err)
+
+			data, err := json.Marshal(original)
+			require.NoError(t, err)
+			require.JSONEq(t, example.result, string(data), "%s -> %s", example.original, example.result)
+		}
+
+		original := new(JSONNode)
+		err := json.Unmarshal([]byte(`{"a": 1, "b": {"c": 2, "d": 3}, "e": [1]}`), original)
+		require.NoError(t, err)
+
+		modified := new(JSONNode)
+		err = json.Unmarshal([]byte(`{"b": {"c": 2, "d": 4}, "e": [1, 2], "f": true}`), modified)
+		require.NoError(t, err)
+
+		patch, err := CreateMergePatch(original, modified)
+		require.NoError(t, err)
+
+		data, err := json.Marshal(patch)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"a": null, "b": {"d": 4}, "e": [1, 2], "f": true}`, string(data))
+	})
+
+	t.Run("create with null", func(t *testing.T) {
+		t.Parallel()
+
+		original := NewFromValue(map[string]interface{}{"a": float64(1)})
+
+		_, err := CreateMergePatch(original, NewFromValue(map[string]interface{}{"a": nil}))
+		require.Equal(t, ErrMergeNull, err)
+
+		_, err = CreateMergePatch(original, NewFromValue(map[string]interface{}{"b": map[string]interface{}{"c": nil}}))
+		require.Equal(t, ErrMergeNull, err)
+
+		// Nulls in arrays are fine
+		_, err = CreateMergePatch(original, NewFromValue(map[string]interface{}{"a": []interface{}{nil}}))
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,251 @@
+package jsonnode
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// errLineReaderClosed is returned by LineReader.Next after Close has been called.
+var errLineReaderClosed = errors.New("jsonnode: LineReader closed")
+
+// LineError is returned by LineReader.Next when a line isn't valid JSON.
+type LineError struct {
+	// Line is the line number, starting at 1. Blank lines are counted.
+	Line int
+
+	// Err is the error decoding the line.
+	Err error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("jsonnode: line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns Err.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// LineReaderOptions controls how a LineReader reads lines.
+type LineReaderOptions struct {
+	// DecoderOptions controls how each line is decoded.
+	DecoderOptions
+
+	// Workers is the number of goroutines lines are decoded on. If it is more than 1, lines are
+	// read ahead and decoded in parallel, but are still returned by Next in order.
+	Workers int
+}
+
+// LineReader reads newline-delimited JSON (NDJSON, or JSON Lines): a stream of JSON values, one
+// per line. Blank lines are skipped.
+// A LineReader is not safe for use by more than one goroutine at a time.
+type LineReader struct {
+	r    *bufio.Reader
+	opts LineReaderOptions
+
+	// read is the number of lines read from r
+	read int
+
+	// line is the line number of the last value returned by Next
+	line int
+
+	// err is returned by every call to Next, once it is set
+	err error
+
+	// Used when decoding in parallel. Each line read has its result channel sent on pending, in
+	// order, and the result is sent on that once the line has been decoded.
+	pending chan chan lineResult
+	done    chan struct{}
+}
+
+// lineResult is the result of reading and decoding a line.
+type lineResult struct {
+	node *JSONNode
+	line int
+	err  error
+
+	// readErr is set if a line couldn't be read. It is io.EOF at the end of the input.
+	readErr error
+}
+
+// lineJob is a line to be decoded.
+type lineJob struct {
+	data   []byte
+	line   int
+	result chan lineResult
+}
+
+// NewLineReader creates a LineReader that reads from r.
+func NewLineReader(r io.Reader) *LineReader {
+	return NewLineReaderWithOptions(r, LineReaderOptions{})
+}
+
+// NewLineReaderWithOptions is like NewLineReader, with lines read as controlled by opts.
+// If opts.Workers is more than 1, Close must be called if the LineReader isn't read until Next
+// returns an error other than a *LineError.
+func NewLineReaderWithOptions(r io.Reader, opts LineReaderOptions) *LineReader {
+	return &LineReader{r: bufio.NewReader(r), opts: opts}
+}
+
+// Next decodes the next line. io.EOF is returned at the end of the input.
+// If the line isn't valid JSON, a *LineError is returned, and Next can be called again to carry on
+// with the line after it. Any other error is returned by every call after it.
+func (lr *LineReader) Next() (*JSONNode, error) {
+	if lr.err != nil {
+		return nil, lr.err
+	}
+
+	var result lineResult
+
+	if lr.opts.Workers > 1 {
+		if lr.pending == nil {
+			lr.start()
+		}
+
+		pending, ok := <-lr.pending
+		if !ok {
+			lr.err = io.EOF
+			return nil, lr.err
+		}
+
+		result = <-pending
+	} else {
+		data, line, err := lr.readLine()
+		if err != nil {
+			result = lineResult{readErr: err}
+		} else {
+			result = lr.decode(data, line)
+		}
+	}
+
+	if result.readErr != nil {
+		lr.err = result.readErr
+		return nil, lr.err
+	}
+
+	lr.line = result.line
+
+	return result.node, result.err
+}
+
+// Line gets the line number of the last value returned by Next, starting at 1.
+func (lr *LineReader) Line() int {
+	return lr.line
+}
+
+// Close stops any goroutines decoding lines in parallel. It does not close the io.Reader the lines
+// are read from, and doesn't interrupt a read from it that is already in progress.
+// After Close, Next returns an error.
+func (lr *LineReader) Close() error {
+	if lr.err == errLineReaderClosed {
+		return nil
+	}
+
+	if lr.done != nil {
+		close(lr.done)
+	}
+
+	lr.err = errLineReaderClosed
+
+	return nil
+}
+
+// readLine reads the next line that isn't blank, without the line ending.
+func (lr *LineReader) readLine() ([]byte, int, error) {
+	for {
+		data, err := lr.r.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(data) == 0) {
+			return nil, 0, err
+		}
+
+		lr.read++
+
+		data = bytes.Trim(data, " \t\r\n")
+		if len(data) > 0 {
+			return data, lr.read, nil
+		}
+	}
+}
+
+// decode decodes a line.
+func (lr *LineReader) decode(data []byte, line int) lineResult {
+	jn, err := Unmarshal(data, lr.opts.DecoderOptions)
+	if err != nil {
+		return lineResult{line: line, err: &LineError{Line: line, Err: err}}
+	}
+
+	return lineResult{node: jn, line: line}
+}
+
+// start starts the goroutines that read and decode lines in parallel.
+func (lr *LineReader) start() {
+	// Limit how far ahead lines are read
+	lr.pending = make(chan chan lineResult, lr.opts.Workers*2)
+	lr.done = make(chan struct{})
+
+	jobs := make(chan lineJob)
+
+	for i := 0; i < lr.opts.Workers; i++ {
+		go func() {
+			for job := range jobs {
+				job.result <- lr.decode(job.data, job.line)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(lr.pending)
+		defer close(jobs)
+
+		for {
+			data, line, err := lr.readLine()
+
+			result := make(chan lineResult, 1)
+
+			select {
+			case lr.pending <- result:
+			case <-lr.done:
+				return
+			}
+
+			if err != nil {
+				result <- lineResult{readErr: err}
+				return
+			}
+
+			select {
+			case jobs <- lineJob{data: data, line: line, result: result}:
+			case <-lr.done:
+				return
+			}
+		}
+	}()
+}
+
+// LineWriter writes newline-delimited JSON (NDJSON, or JSON Lines): a stream of JSON values, one
+// per line.
+type LineWriter struct {
+	w io.Writer
+}
+
+// NewLineWriter creates a LineWriter that writes to w.
+func NewLineWriter(w io.Writer) *LineWriter {
+	return &LineWriter{w: w}
+}
+
+// WriteNode writes the value held by jn as compact JSON, followed by a newline. The line is
+// written to the io.Writer with a single call to Write.
+func (lw *LineWriter) WriteNode(jn *JSONNode) error {
+	data, err := json.Marshal(jn)
+	if err != nil {
+		return err
+	}
+
+	_, err = lw.w.Write(append(data, '\n'))
+
+	return err
+}
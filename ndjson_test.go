@@ -0,0 +1,223 @@
+package jsonnode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// failingReader returns its data, then err.
+type failingReader struct {
+	data []byte
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+
+	return n, nil
+}
+
+func TestLineReader(t *testing.T) {
+	t.Parallel()
+
+	type line struct {
+		Line int
+		JSON string
+		Err  bool
+	}
+
+	// all calls Next until an error other than a *LineError
+	all := func(t *testing.T, lr *LineReader) ([]line, error) {
+		var lines []line
+
+		for {
+			jn, err := lr.Next()
+
+			var lineErr *LineError
+			switch {
+			case errors.As(err, &lineErr):
+				require.Nil(t, jn)
+				require.Equal(t, lr.Line(), lineErr.Line)
+				require.True(t, strings.HasPrefix(err.Error(), fmt.Sprintf("jsonnode: line %d: ", lineErr.Line)), err.Error())
+				lines = append(lines, line{Line: lineErr.Line, Err: true})
+
+			case err != nil:
+				return lines, err
+
+			default:
+				data, err := json.Marshal(jn)
+				require.NoError(t, err)
+				lines = append(lines, line{Line: lr.Line(), JSON: string(data)})
+			}
+		}
+	}
+
+	const input = "{\"b\": 1, \"a\": [true, null]}\n" +
+		"\n" +
+		"  \"two\"  \r\n" +
+		"{\"bad\": }\n" +
+		"\t\n" +
+		"3.50\n" +
+		"[1] [2]\n" +
+		"{\"last\": \"no newline\"}"
+
+	expected := []line{
+		{Line: 1, JSON: `{"b":1,"a":[true,null]}`},
+		{Line: 3, JSON: `"two"`},
+		{Line: 4, Err: true},
+		{Line: 6, JSON: `3.5`},
+		{Line: 7, Err: true},
+		{Line: 8, JSON: `{"last":"no newline"}`},
+	}
+
+	for _, workers := range []int{0, 1, 2, 8} {
+		workers := workers
+
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			t.Parallel()
+
+			lr := NewLineReaderWithOptions(strings.NewReader(input), LineReaderOptions{Workers: workers})
+			defer lr.Close()
+
+			lines, err := all(t, lr)
+			require.Equal(t, io.EOF, err)
+			require.Equal(t, expected, lines)
+
+			_, err = lr.Next()
+			require.Equal(t, io.EOF, err)
+
+			// Lots of lines, to check the order
+			var buf bytes.Buffer
+			for i := 0; i < 1000; i++ {
+				fmt.Fprintf(&buf, "{\"i\": %d}\n", i)
+			}
+
+			lr = NewLineReaderWithOptions(&buf, LineReaderOptions{Workers: workers})
+			defer lr.Close()
+
+			for i := 0; i < 1000; i++ {
+				jn, err := lr.Next()
+				require.NoError(t, err)
+				require.Equal(t, i+1, lr.Line())
+				require.Equal(t, float64(i), jn.Get("i").Value())
+			}
+
+			_, err = lr.Next()
+			require.Equal(t, io.EOF, err)
+
+			// An error reading
+			readErr := errors.New("read failed")
+			lr = NewLineReaderWithOptions(&failingReader{data: []byte("1\n2\n3"), err: readErr}, LineReaderOptions{Workers: workers})
+			defer lr.Close()
+
+			lines, err = all(t, lr)
+			require.Equal(t, readErr, err)
+			require.Equal(t, []line{{Line: 1, JSON: `1`}, {Line: 2, JSON: `2`}}, lines)
+
+			_, err = lr.Next()
+			require.Equal(t, readErr, err)
+
+			// Stopping early
+			lr = NewLineReaderWithOptions(strings.NewReader(strings.Repeat("[]\n", 100)), LineReaderOptions{Workers: workers})
+			_, err = lr.Next()
+			require.NoError(t, err)
+
+			require.NoError(t, lr.Close())
+			require.NoError(t, lr.Close())
+
+			_, err = lr.Next()
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("decoder options", func(t *testing.T) {
+		t.Parallel()
+
+		lr := NewLineReaderWithOptions(strings.NewReader("1.50\n{\"a\": 2.0}\n"), LineReaderOptions{
+			DecoderOptions: DecoderOptions{UseNumber: true, Lazy: true},
+			Workers:        2,
+		})
+		defer lr.Close()
+
+		jn, err := lr.Next()
+		require.NoError(t, err)
+		require.Equal(t, json.Number("1.50"), jn.Value())
+
+		jn, err = lr.Next()
+		require.NoError(t, err)
+		require.Equal(t, json.Number("2.0"), jn.Get("a").Value())
+	})
+}
+
+func TestLineWriter(t *testing.T) {
+	t.Parallel()
+
+	lazy, err := Unmarshal([]byte(`{ "lazy" : [ 1, 2 ] }`), DecoderOptions{Lazy: true})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	lw := NewLineWriter(&buf)
+
+	for _, jn := range []*JSONNode{
+		NewFromValue(map[string]interface{}{"multi\nline": "a\nb"}),
+		lazy,
+		NewFromValue(nil),
+		NewFromValue([]interface{}{1, "two"}),
+	} {
+		require.NoError(t, lw.WriteNode(jn))
+	}
+
+	require.Equal(t, "{\"multi\\nline\":\"a\\nb\"}\n{\"lazy\":[1,2]}\nnull\n[1,\"two\"]\n", buf.String())
+
+	// What is written can be read back
+	lr := NewLineReader(&buf)
+
+	jn, err := lr.Next()
+	require.NoError(t, err)
+	require.Equal(t, "a\nb", jn.Get("multi\nline").Value())
+}
+
+func ExampleLineReader() {
+	r := strings.NewReader(`{"level": "info", "msg": "started"}
+{"level": "error", "msg": "failed"}
+{"level": "info", "msg": "stopped"}
+`)
+
+	lr := NewLineReaderWithOptions(r, LineReaderOptions{Workers: 4})
+	defer lr.Close()
+
+	lw := NewLineWriter(os.Stdout)
+
+	for {
+		entry, err := lr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			panic(err)
+		}
+
+		if entry.Get("level").Value() == "error" {
+			if err := lw.WriteNode(entry); err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	// Output:
+	// {"level":"error","msg":"failed"}
+}
@@ -0,0 +1,292 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNotNumber is returned when a number is needed, but the node is something else.
+	ErrNotNumber = errors.New("jsonnode: not a JSON number")
+
+	// ErrOverflow is returned when a number is too big (or too small) for the requested type.
+	ErrOverflow = errors.New("jsonnode: number out of range")
+
+	// ErrPrecisionLoss is returned when a number can't be converted to the requested type without
+	// losing part of it, such as the fraction of 1.5 when an integer is requested.
+	ErrPrecisionLoss = errors.New("jsonnode: number would lose precision")
+)
+
+// ValueAsInt64 gets the value of the current node as an int64.
+// ErrPrecisionLoss is returned if the number is not an integer, and ErrOverflow if it doesn't fit.
+// Use DecoderOptions.UseNumber to get integers beyond 2^53 without them being rounded.
+func (jn *JSONNode) ValueAsInt64() (int64, error) {
+	if n, ok := jn.OrderedValue().(json.Number); ok {
+		if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+			return i, nil
+		}
+	}
+
+	i, err := jn.ValueAsBigInt()
+	if err != nil {
+		return 0, err
+	}
+
+	if !i.IsInt64() {
+		return 0, fmt.Errorf("%w: %v does not fit in an int64", ErrOverflow, i)
+	}
+
+	return i.Int64(), nil
+}
+
+// ValueAsUint64 gets the value of the current node as a uint64.
+// ErrPrecisionLoss is returned if the number is not an integer, and ErrOverflow if it doesn't fit
+// (including if it is negative).
+func (jn *JSONNode) ValueAsUint64() (uint64, error) {
+	if n, ok := jn.OrderedValue().(json.Number); ok {
+		if i, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+			return i, nil
+		}
+	}
+
+	i, err := jn.ValueAsBigInt()
+	if err != nil {
+		return 0, err
+	}
+
+	if !i.IsUint64() {
+		return 0, fmt.Errorf("%w: %v does not fit in a uint64", ErrOverflow, i)
+	}
+
+	return i.Uint64(), nil
+}
+
+// ValueAsBigInt gets the value of the current node as a *big.Int.
+// ErrPrecisionLoss is returned if the number is not an integer.
+func (jn *JSONNode) ValueAsBigInt() (*big.Int, error) {
+	value := jn.OrderedValue()
+
+	r, err := numberRat(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.IsInt() {
+		return nil, fmt.Errorf("%w: %v is not an integer", ErrPrecisionLoss, value)
+	}
+
+	return new(big.Int).Set(r.Num()), nil
+}
+
+// ValueAsBigFloat gets the value of the current node as a *big.Float.
+// Numbers with an exact binary representation are returned exactly. Other numbers (such as 0.1)
+// are rounded, keeping more significant digits than the number was written with.
+func (jn *JSONNode) ValueAsBigFloat() (*big.Float, error) {
+	value := jn.OrderedValue()
+
+	if f, ok := value.(float64); ok {
+		return big.NewFloat(f), nil
+	}
+
+	r, err := numberRat(value)
+	if err != nil {
+		return nil, err
+	}
+
+	prec := uint(64)
+	if bits := uint(r.Num().BitLen()); bits > prec {
+		prec = bits
+	}
+
+	f := new(big.Float).SetPrec(prec).SetRat(r)
+
+	if f.Acc() != big.Exact {
+		// There's no exact binary representation, so keep at least as many digits as the literal has
+		// (each decimal digit needs less than 4 bits)
+		if digits := uint(len(value.(json.Number)))*4 + 64; digits > prec {
+			f.SetPrec(digits).SetRat(r)
+		}
+	}
+
+	return f, nil
+}
+
+// ValueAsNumber gets the value of the current node as a json.Number.
+// If the JSON was decoded with DecoderOptions.UseNumber, this is the number exactly as it was in
+// the JSON. Otherwise it is the float64 as encoding/json would marshal it.
+func (jn *JSONNode) ValueAsNumber() (json.Number, error) {
+	value := jn.OrderedValue()
+
+	switch value.(type) {
+	case float64, json.Number:
+		return numberLiteral(value), nil
+	}
+
+	return "", fmt.Errorf("%w: found %v", ErrNotNumber, kindOf(value))
+}
+
+// formatFloat formats f the same way encoding/json does: the shortest decimal that is f, with an
+// exponent only if f is very large or very small.
+func formatFloat(f float64) string {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+
+	s := strconv.FormatFloat(f, format, -1, 64)
+
+	if format == 'e' {
+		// 1e-07 is 1e-7
+		n := len(s)
+		if n >= 4 && s[n-4] == 'e' && s[n-3] == '-' && s[n-2] == '0' {
+			s = s[:n-2] + s[n-1:]
+		}
+	}
+
+	return s
+}
+
+// CompareNumbers compares two numbers held by nodes, which may be float64 or json.Number (see
+// DecoderOptions.UseNumber). The result is -1 if a < b, 0 if a == b, and 1 if a > b.
+// A float64 is compared as the shortest decimal that is that float64, so 0.1 is the same whether
+// or not it was decoded with UseNumber. false is returned if either is not a number.
+func CompareNumbers(a, b interface{}) (int, bool) {
+	aFloat, aIsFloat := a.(float64)
+	bFloat, bIsFloat := b.(float64)
+
+	if aIsFloat && bIsFloat {
+		return compareFloats(aFloat, bFloat), true
+	}
+
+	aNum, aFloat, ok := numberParts(a)
+	if !ok {
+		return 0, false
+	}
+
+	bNum, bFloat, ok := numberParts(b)
+	if !ok {
+		return 0, false
+	}
+
+	if aNum == bNum {
+		return 0, true
+	}
+
+	// Rounding to a float64 doesn't change which number is larger, so the exact values only need
+	// comparing if they are the same at float64 precision
+	if aFloat != bFloat {
+		return compareFloats(aFloat, bFloat), true
+	}
+
+	aRat, err := numberRat(aNum)
+	if err != nil {
+		return 0, false
+	}
+
+	bRat, err := numberRat(bNum)
+	if err != nil {
+		return 0, false
+	}
+
+	return aRat.Cmp(bRat), true
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// numberParts gets a number (float64 or json.Number) as a json.Number, and at float64 precision.
+// A json.Number too large for a float64 is infinite.
+func numberParts(value interface{}) (json.Number, float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return numberLiteral(v), v, true
+
+	case json.Number:
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil && !errors.Is(err, strconv.ErrRange) {
+			return "", 0, false
+		}
+
+		return v, f, true
+	}
+
+	return "", 0, false
+}
+
+// canonicalDecimal gets a number literal in a form that is the same for all literals with the same
+// value, such as "1.50" and "15e-1". false is returned if it isn't a valid number literal.
+func canonicalDecimal(n json.Number) (string, bool) {
+	s := string(n)
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign = "-"
+		s = s[1:]
+	}
+
+	exp := 0
+
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+
+		exp, err = strconv.Atoi(s[i+1:])
+		if err != nil {
+			return "", false
+		}
+
+		s = s[:i]
+	}
+
+	digits := s
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		digits = s[:i] + s[i+1:]
+		exp -= len(s) - i - 1
+	}
+
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" {
+		return "0", true
+	}
+
+	trimmed := strings.TrimRight(digits, "0")
+	exp += len(digits) - len(trimmed)
+
+	return sign + trimmed + "e" + strconv.Itoa(exp), true
+}
+
+// numberRat gets the exact value of a number, which may be a float64 or a json.Number.
+func numberRat(value interface{}) (*big.Rat, error) {
+	switch v := value.(type) {
+	case float64:
+		r := new(big.Rat).SetFloat64(v)
+		if r == nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotNumber, v)
+		}
+
+		return r, nil
+
+	case json.Number:
+		r, ok := new(big.Rat).SetString(string(v))
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrNotNumber, string(v))
+		}
+
+		return r, nil
+	}
+
+	return nil, fmt.Errorf("%w: found %v", ErrNotNumber, kindOf(value))
+}
@@ -0,0 +1,286 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalUseNumber(t *testing.T) {
+	t.Parallel()
+
+	const input = `{"id":9007199254740993,"price":1.10,"big":123456789012345678901234567890}`
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		jn, err := Unmarshal([]byte(input), DecoderOptions{UseNumber: true})
+		require.NoError(t, err)
+
+		data, err := json.Marshal(jn)
+		require.NoError(t, err)
+		require.Equal(t, input, string(data))
+
+		id, err := jn.Get("id").ValueAsInt64()
+		require.NoError(t, err)
+		require.Equal(t, int64(9007199254740993), id)
+
+		f, ok := jn.Get("price").ValueAsFloat64()
+		require.True(t, ok)
+		require.Equal(t, 1.1, f)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+
+		jn, err := Unmarshal([]byte(input), DecoderOptions{})
+		require.NoError(t, err)
+
+		data, err := json.Marshal(jn)
+		require.NoError(t, err)
+		require.Equal(t, `{"id":9007199254740992,"price":1.1,"big":1.2345678901234568e+29}`, string(data))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Unmarshal([]byte(`{"a":`), DecoderOptions{UseNumber: true})
+		require.Error(t, err)
+	})
+
+	t.Run("equal", func(t *testing.T) {
+		t.Parallel()
+
+		jn, err := Unmarshal([]byte(`{"a":1.0}`), DecoderOptions{UseNumber: true})
+		require.NoError(t, err)
+
+		// A float64 in the patch tests equal to a json.Number in the document
+		require.NoError(t, jn.ApplyPatch([]byte(`[{"op":"test","path":"/a","value":1}]`)))
+		require.Error(t, jn.ApplyPatch([]byte(`[{"op":"test","path":"/a","value":2}]`)))
+	})
+}
+
+func TestJSONNodeValueAsNumbers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value    interface{}
+		int64    int64
+		int64Err error
+		uint64   uint64
+		uintErr  error
+		bigInt   string
+		bigErr   error
+		number   json.Number
+	}{
+		{
+			value:  float64(42),
+			int64:  42,
+			uint64: 42,
+			bigInt: "42",
+			number: "42",
+		},
+		{
+			value:   float64(-1),
+			int64:   -1,
+			uintErr: ErrOverflow,
+			bigInt:  "-1",
+			number:  "-1",
+		},
+		{
+			value:    1.5,
+			int64Err: ErrPrecisionLoss,
+			uintErr:  ErrPrecisionLoss,
+			bigErr:   ErrPrecisionLoss,
+			number:   "1.5",
+		},
+		{
+			value:    1e20,
+			int64Err: ErrOverflow,
+			uintErr:  ErrOverflow,
+			bigInt:   "100000000000000000000",
+			number:   "100000000000000000000",
+		},
+		{
+			value:  float64(1234567),
+			int64:  1234567,
+			uint64: 1234567,
+			bigInt: "1234567",
+			number: "1234567",
+		},
+		{
+			value:  float64(1e8),
+			int64:  100000000,
+			uint64: 100000000,
+			bigInt: "100000000",
+			number: "100000000",
+		},
+		{
+			value:    1e21,
+			int64Err: ErrOverflow,
+			uintErr:  ErrOverflow,
+			bigInt:   "1000000000000000000000",
+			number:   "1e+21",
+		},
+		{
+			value:    0.000001,
+			int64Err: ErrPrecisionLoss,
+			uintErr:  ErrPrecisionLoss,
+			bigErr:   ErrPrecisionLoss,
+			number:   "0.000001",
+		},
+		{
+			value:    -1e-7,
+			int64Err: ErrPrecisionLoss,
+			uintErr:  ErrPrecisionLoss,
+			bigErr:   ErrPrecisionLoss,
+			number:   "-1e-7",
+		},
+		{
+			value:    json.Number("18446744073709551615"),
+			int64Err: ErrOverflow,
+			uint64:   18446744073709551615,
+			bigInt:   "18446744073709551615",
+			number:   "18446744073709551615",
+		},
+		{
+			value:  json.Number("1.0e3"),
+			int64:  1000,
+			uint64: 1000,
+			bigInt: "1000",
+			number: "1.0e3",
+		},
+		{
+			value:    json.Number("0.1"),
+			int64Err: ErrPrecisionLoss,
+			uintErr:  ErrPrecisionLoss,
+			bigErr:   ErrPrecisionLoss,
+			number:   "0.1",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(string(mustNumber(t, test.value)), func(t *testing.T) {
+			t.Parallel()
+
+			jn := NewFromValue(test.value)
+
+			i, err := jn.ValueAsInt64()
+			if test.int64Err != nil {
+				require.True(t, errors.Is(err, test.int64Err), "%v", err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, test.int64, i)
+			}
+
+			u, err := jn.ValueAsUint64()
+			if test.uintErr != nil {
+				require.True(t, errors.Is(err, test.uintErr), "%v", err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, test.uint64, u)
+			}
+
+			b, err := jn.ValueAsBigInt()
+			if test.bigErr != nil {
+				require.True(t, errors.Is(err, test.bigErr), "%v", err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, test.bigInt, b.String())
+			}
+
+			n, err := jn.ValueAsNumber()
+			require.NoError(t, err)
+			require.Equal(t, test.number, n)
+
+			if _, ok := test.value.(float64); ok && test.int64Err == nil {
+				// Integers aren't given an exponent
+				i, err = n.Int64()
+				require.NoError(t, err)
+				require.Equal(t, test.int64, i)
+			}
+		})
+	}
+
+	t.Run("big float", func(t *testing.T) {
+		t.Parallel()
+
+		f, err := NewFromValue(json.Number("123456789012345678901234567890.5")).ValueAsBigFloat()
+		require.NoError(t, err)
+		require.Equal(t, big.Exact, f.Acc())
+		require.Equal(t, "123456789012345678901234567890.5", f.Text('f', -1))
+
+		f, err = NewFromValue(json.Number("0.1")).ValueAsBigFloat()
+		require.NoError(t, err)
+		require.Equal(t, "0.1", f.Text('g', 20))
+
+		f, err = NewFromValue(0.25).ValueAsBigFloat()
+		require.NoError(t, err)
+		require.Equal(t, "0.25", f.Text('g', -1))
+	})
+
+	t.Run("not a number", func(t *testing.T) {
+		t.Parallel()
+
+		jn := NewFromValue("42")
+
+		_, err := jn.ValueAsInt64()
+		require.True(t, errors.Is(err, ErrNotNumber), "%v", err)
+
+		_, err = jn.ValueAsUint64()
+		require.True(t, errors.Is(err, ErrNotNumber), "%v", err)
+
+		_, err = jn.ValueAsBigInt()
+		require.True(t, errors.Is(err, ErrNotNumber), "%v", err)
+
+		_, err = jn.ValueAsBigFloat()
+		require.True(t, errors.Is(err, ErrNotNumber), "%v", err)
+
+		_, err = jn.ValueAsNumber()
+		require.True(t, errors.Is(err, ErrNotNumber), "%v", err)
+
+		var nilNode *JSONNode
+		_, err = nilNode.ValueAsInt64()
+		require.True(t, errors.Is(err, ErrNotNumber), "%v", err)
+	})
+}
+
+func mustNumber(t *testing.T, value interface{}) json.Number {
+	n, err := NewFromValue(value).ValueAsNumber()
+	require.NoError(t, err)
+
+	return n
+}
+
+func TestCompareNumbers(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		a, b     interface{}
+		expected int
+	}{
+		{1.0, 2.0, -1},
+		{2.0, 1.0, 1},
+		{19.99, json.Number("19.99"), 0},
+		{json.Number("1.0"), 1.0, 0},
+		{json.Number("15e-1"), json.Number("1.50"), 0},
+		{json.Number("9007199254740993"), 9007199254740992.0, 1},
+		{json.Number("1e400"), 1e308, 1},
+		{json.Number("-1e400"), -1e308, -1},
+	} {
+		cmp, ok := CompareNumbers(test.a, test.b)
+		require.True(t, ok, "%v, %v", test.a, test.b)
+		require.Equal(t, test.expected, cmp, "%v, %v", test.a, test.b)
+	}
+
+	_, ok := CompareNumbers(1.0, "1")
+	require.False(t, ok)
+
+	_, ok = CompareNumbers(json.Number("x"), 1.0)
+	require.False(t, ok)
+}
@@ -0,0 +1,157 @@
+package jsonnode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+var _ json.Marshaler = (*Object)(nil)
+var _ json.Unmarshaler = (*Object)(nil)
+
+// Object is a JSON object that keeps its members in order.
+// Members unmarshalled from JSON are kept in the order they appear in the document,
+// and new members are added to the end.
+// This is how JSON objects are represented in the value of a *JSONNode (see JSONNode.OrderedValue).
+type Object struct {
+	keys   []string
+	values map[string]interface{}
+
+	// doc is the document of the nodes that have seen this object, if any (see adopt).
+	doc *document
+}
+
+// NewObject creates a new, empty, Object.
+func NewObject() *Object {
+	return &Object{
+		values: make(map[string]interface{}),
+	}
+}
+
+// Len gets the number of members in the object.
+func (o *Object) Len() int {
+	if o == nil {
+		return 0
+	}
+
+	return len(o.keys)
+}
+
+// Keys gets the names of the members of the object, in order.
+func (o *Object) Keys() []string {
+	if o == nil {
+		return nil
+	}
+
+	keys := make([]string, len(o.keys))
+	copy(keys, o.keys)
+
+	return keys
+}
+
+// Get gets the value of the specified member, and whether it exists.
+func (o *Object) Get(key string) (interface{}, bool) {
+	if o == nil {
+		return nil, false
+	}
+
+	value, ok := o.values[key]
+
+	return value, ok
+}
+
+// Set sets the value of the specified member.
+// If the member already exists, it keeps its place. Otherwise, it is added to the end.
+func (o *Object) Set(key string, value interface{}) {
+	o.set(key, value)
+	o.invalidate()
+}
+
+// set is like Set, for an object that no JSONNode can be looking at yet, such as while building it.
+func (o *Object) set(key string, value interface{}) {
+	if o.values == nil {
+		o.values = make(map[string]interface{})
+	}
+
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+
+	o.values[key] = value
+}
+
+// Delete removes the specified member, if it exists.
+func (o *Object) Delete(key string) {
+	if o == nil {
+		return
+	}
+
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+
+	delete(o.values, key)
+	o.invalidate()
+
+	for i := range o.keys {
+		if o.keys[i] == key {
+			// Don't modify the backing array in place, in case it's shared with a copy of this object
+			o.keys = append(o.keys[:i:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// MarshalJSON marshals the object to JSON, with its members in order.
+func (o *Object) MarshalJSON() ([]byte, error) {
+	if o == nil {
+		return []byte("null"), nil
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		keyJSON, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(keyJSON)
+		buf.WriteByte(':')
+
+		valueJSON, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(valueJSON)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON unmarshals a JSON object, keeping its members in order.
+func (o *Object) UnmarshalJSON(data []byte) error {
+	value, err := decode(data, DecoderOptions{})
+	if err != nil {
+		return err
+	}
+
+	obj, ok := value.(*Object)
+	if !ok {
+		return fmt.Errorf("jsonnode: cannot unmarshal a JSON %v into an Object", kindOf(value))
+	}
+
+	o.keys, o.values = obj.keys, obj.values
+	o.invalidate()
+
+	return nil
+}
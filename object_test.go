@@ -0,0 +1,87 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestObject(t *testing.T) {
+	t.Parallel()
+
+	t.Run("members", func(t *testing.T) {
+		t.Parallel()
+
+		obj := NewObject()
+		require.Zero(t, obj.Len())
+
+		obj.Set("b", float64(1))
+		obj.Set("a", "two")
+		obj.Set("c", nil)
+		obj.Set("b", float64(3))
+
+		require.Equal(t, 3, obj.Len())
+		require.Equal(t, []string{"b", "a", "c"}, obj.Keys())
+
+		value, ok := obj.Get("b")
+		require.True(t, ok)
+		require.Equal(t, float64(3), value)
+
+		// Exists, but null
+		value, ok = obj.Get("c")
+		require.True(t, ok)
+		require.Nil(t, value)
+
+		_, ok = obj.Get("d")
+		require.False(t, ok)
+
+		keys := obj.Keys()
+		obj.Delete("a")
+		obj.Delete("nope")
+
+		require.Equal(t, []string{"b", "c"}, obj.Keys())
+
+		// Keys returns a copy
+		require.Equal(t, []string{"b", "a", "c"}, keys)
+
+		data, err := json.Marshal(obj)
+		require.NoError(t, err)
+		require.Equal(t, `{"b":3,"c":null}`, string(data))
+	})
+
+	t.Run("zero value", func(t *testing.T) {
+		t.Parallel()
+
+		var obj Object
+		obj.Set("a", true)
+		require.Equal(t, []string{"a"}, obj.Keys())
+
+		var nilObj *Object
+		require.Zero(t, nilObj.Len())
+		require.Nil(t, nilObj.Keys())
+
+		data, err := json.Marshal(nilObj)
+		require.NoError(t, err)
+		require.Equal(t, `null`, string(data))
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+		t.Parallel()
+
+		obj := new(Object)
+		err := json.Unmarshal([]byte(`{"z": 1, "y": {"x": [], "w": "<&>"}, "z": 2}`), obj)
+		require.NoError(t, err)
+
+		// Duplicates keep the first position, but the last value
+		require.Equal(t, []string{"z", "y"}, obj.Keys())
+
+		data, err := json.Marshal(obj)
+		require.NoError(t, err)
+		// HTML characters are escaped, just like encoding/json does
+		require.Equal(t, `{"z":2,"y":{"x":[],"w":"\u003c\u0026\u003e"}}`, string(data))
+
+		err = json.Unmarshal([]byte(`[]`), obj)
+		require.Error(t, err)
+	})
+}
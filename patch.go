@@ -0,0 +1,315 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// JSON Patch operations, as defined in RFC 6902.
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// ErrTestFailed is returned when a JSON Patch "test" operation finds a different value than expected.
+var ErrTestFailed = errors.New("jsonnode: test failed")
+
+// Operation is a single operation in a JSON Patch (RFC 6902) document.
+type Operation struct {
+	Op   string
+	Path string
+
+	// From is only used by the "move" and "copy" operations.
+	From string
+
+	// Value is only used by the "add", "replace", and "test" operations. nil is a JSON null.
+	// It can be anything FromValue accepts.
+	Value interface{}
+}
+
+var _ json.Marshaler = Operation{}
+
+// MarshalJSON marshals the operation to a JSON object with only the members the operation uses.
+func (op Operation) MarshalJSON() ([]byte, error) {
+	obj := NewObject()
+	obj.set("op", op.Op)
+	obj.set("path", op.Path)
+
+	switch op.Op {
+	case OpMove, OpCopy:
+		obj.set("from", op.From)
+
+	case OpAdd, OpReplace, OpTest:
+		obj.set("value", op.Value)
+	}
+
+	return obj.MarshalJSON()
+}
+
+// Patch is a JSON Patch (RFC 6902) document.
+type Patch []Operation
+
+// PatchError describes why a JSON Patch could not be applied.
+type PatchError struct {
+	Index int    // The index of the operation in the patch
+	Op    string // The operation
+	Path  string // The path the operation applies to
+	Err   error  // What went wrong
+}
+
+func (e *PatchError) Error() string {
+	if e.Op == "" {
+		return fmt.Sprintf("jsonnode: patch operation %d: %v", e.Index, e.Err)
+	}
+
+	return fmt.Sprintf("jsonnode: patch operation %d (%s %q): %v", e.Index, e.Op, e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// ParsePatch parses a JSON Patch (RFC 6902) document.
+func ParsePatch(data []byte) (Patch, error) {
+	jn := new(JSONNode)
+
+	err := json.Unmarshal(data, jn)
+	if err != nil {
+		return nil, err
+	}
+
+	return patchFromNode(jn)
+}
+
+// patchFromNode converts an unmarshalled JSON Patch document into a Patch, validating it along the way.
+func patchFromNode(jn *JSONNode) (Patch, error) {
+	nodes, ok := jn.ValueAsSlice()
+	if !ok {
+		return nil, errors.New("jsonnode: a JSON Patch must be a JSON array")
+	}
+
+	patch := make(Patch, len(nodes))
+
+	for i, node := range nodes {
+		if _, ok := node.ValueAsNode(); !ok {
+			return nil, &PatchError{Index: i, Err: errors.New("operation must be a JSON object")}
+		}
+
+		op := &patch[i]
+
+		op.Op, ok = node.Get("op").ValueAsString()
+		if !ok {
+			return nil, &PatchError{Index: i, Err: errors.New(`"op" is missing or not a string`)}
+		}
+
+		op.Path, ok = node.Get("path").ValueAsString()
+		if !ok {
+			return nil, &PatchError{Index: i, Op: op.Op, Err: errors.New(`"path" is missing or not a string`)}
+		}
+
+		switch op.Op {
+		case OpAdd, OpReplace, OpTest:
+			value := node.Get("value")
+			if value == nil {
+				return nil, &PatchError{Index: i, Op: op.Op, Path: op.Path, Err: errors.New(`"value" is missing`)}
+			}
+
+			op.Value = value.OrderedValue()
+
+		case OpMove, OpCopy:
+			op.From, ok = node.Get("from").ValueAsString()
+			if !ok {
+				return nil, &PatchError{Index: i, Op: op.Op, Path: op.Path, Err: errors.New(`"from" is missing or not a string`)}
+			}
+
+		case OpRemove:
+
+		default:
+			return nil, &PatchError{Index: i, Op: op.Op, Path: op.Path, Err: errors.New("unknown operation")}
+		}
+	}
+
+	return patch, nil
+}
+
+// ApplyPatch applies a JSON Patch (RFC 6902) to this node.
+// patch can be a Patch, a JSON Patch document as a []byte, or a *JSONNode holding a JSON Patch document.
+// The patch is applied atomically; if any operation fails, this node is left untouched and a
+// *PatchError is returned saying which operation failed.
+func (jn *JSONNode) ApplyPatch(patch interface{}) error {
+	if jn == nil {
+		return ErrNilNode
+	}
+
+	var ops Patch
+	var err error
+
+	switch p := patch.(type) {
+	case Patch:
+		ops = p
+
+	case []Operation:
+		ops = p
+
+	case []byte:
+		ops, err = ParsePatch(p)
+
+	case json.RawMessage:
+		ops, err = ParsePatch(p)
+
+	case *JSONNode:
+		ops, err = patchFromNode(p)
+
+	default:
+		err = fmt.Errorf("jsonnode: cannot use %T as a JSON Patch", patch)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Work on a copy, so nothing changes if any of the operations fail
+	doc := NewFromValue(jn.OrderedValue())
+
+	for i, op := range ops {
+		err = doc.applyOperation(op)
+		if err != nil {
+			return &PatchError{Index: i, Op: op.Op, Path: op.Path, Err: err}
+		}
+	}
+
+	return jn.setValue(doc.data)
+}
+
+func (jn *JSONNode) applyOperation(op Operation) error {
+	var value interface{}
+
+	switch op.Op {
+	case OpAdd, OpReplace, OpTest:
+		var err error
+
+		value, err = toValue(op.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch op.Op {
+	case OpAdd:
+		return jn.addPointer(op.Path, value)
+
+	case OpRemove:
+		return jn.DeletePointer(op.Path)
+
+	case OpReplace:
+		node, err := jn.mustPointer(op.Path)
+		if err != nil {
+			return err
+		}
+
+		return node.setValue(value)
+
+	case OpMove:
+		if op.From == op.Path {
+			_, err := jn.mustPointer(op.From)
+			return err
+		}
+
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return errors.New("cannot move a value into one of its own children")
+		}
+
+		from, err := jn.mustPointer(op.From)
+		if err != nil {
+			return err
+		}
+
+		value := from.OrderedValue()
+
+		err = jn.DeletePointer(op.From)
+		if err != nil {
+			return err
+		}
+
+		return jn.addPointer(op.Path, value)
+
+	case OpCopy:
+		from, err := jn.mustPointer(op.From)
+		if err != nil {
+			return err
+		}
+
+		return jn.addPointer(op.Path, copyValue(from.OrderedValue()))
+
+	case OpTest:
+		node, err := jn.mustPointer(op.Path)
+		if err != nil {
+			return err
+		}
+
+		if !equalValues(node.OrderedValue(), value) {
+			return ErrTestFailed
+		}
+
+		return nil
+	}
+
+	return errors.New("unknown operation")
+}
+
+// mustPointer is like Pointer, but returns an error saying why the node could not be found.
+func (jn *JSONNode) mustPointer(ptr string) (*JSONNode, error) {
+	tokens, err := parsePointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+
+	node := jn.resolve(tokens)
+	if node == nil {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, ptr)
+	}
+
+	return node, nil
+}
+
+// addPointer implements the JSON Patch "add" operation, which (unlike SetPointer) inserts into JSON
+// arrays rather than replacing elements.
+func (jn *JSONNode) addPointer(ptr string, value interface{}) error {
+	tokens, err := parsePointer(ptr)
+	if err != nil {
+		return err
+	}
+
+	if len(tokens) == 0 {
+		return jn.setValue(value)
+	}
+
+	parent := jn.resolve(tokens[:len(tokens)-1])
+	if parent == nil {
+		return fmt.Errorf("%w: %q", ErrNotFound, ptr)
+	}
+
+	last := tokens[len(tokens)-1]
+
+	if _, ok := parent.shallowValue().([]interface{}); !ok {
+		return parent.Set(last, value)
+	}
+
+	if last == "-" {
+		return parent.Append(value)
+	}
+
+	i, ok := parseArrayIndex(last)
+	if !ok {
+		return fmt.Errorf("%w %q: %q is not an array index", ErrInvalidPointer, ptr, last)
+	}
+
+	return parent.InsertAt(i, value)
+}
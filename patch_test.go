@@ -0,0 +1,281 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONNodeApplyPatch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rfc examples", func(t *testing.T) {
+		t.Parallel()
+
+		// The examples from RFC 6902, appendix A
+		for _, test := range []struct {
+			name, doc, patch, expected string
+		}{
+			{
+				name:     "A.1 adding an object member",
+				doc:      `{"foo": "bar"}`,
+				patch:    `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+				expected: `{"baz": "qux", "foo": "bar"}`,
+			},
+			{
+				name:     "A.2 adding an array element",
+				doc:      `{"foo": ["bar", "baz"]}`,
+				patch:    `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+				expected: `{"foo": ["bar", "qux", "baz"]}`,
+			},
+			{
+				name:     "A.3 removing an object member",
+				doc:      `{"baz": "qux", "foo": "bar"}`,
+				patch:    `[{"op": "remove", "path": "/baz"}]`,
+				expected: `{"foo": "bar"}`,
+			},
+			{
+				name:     "A.4 removing an array element",
+				doc:      `{"foo": ["bar", "qux", "baz"]}`,
+				patch:    `[{"op": "remove", "path": "/foo/1"}]`,
+				expected: `{"foo": ["bar", "baz"]}`,
+			},
+			{
+				name:     "A.5 replacing a value",
+				doc:      `{"baz": "qux", "foo": "bar"}`,
+				patch:    `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+				expected: `{"baz": "boo", "foo": "bar"}`,
+			},
+			{
+				name:     "A.6 moving a value",
+				doc:      `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+				patch:    `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+				expected: `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+			},
+			{
+				name:     "A.7 moving an array element",
+				doc:      `{"foo": ["all", "grass", "cows", "eat"]}`,
+				patch:    `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+				expected: `{"foo": ["all", "cows", "eat", "grass"]}`,
+			},
+			{
+				name: "A.8 testing a value: success",
+				doc:  `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+				patch: `[
+					{"op": "test", "path": "/baz", "value": "qux"},
+					{"op": "test", "path": "/foo/1", "value": 2}
+				]`,
+				expected: `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			},
+			{
+				name:     "A.10 adding a nested member object",
+				doc:      `{"foo": "bar"}`,
+				patch:    `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`,
+				expected: `{"foo": "bar", "child": {"grandchild": {}}}`,
+			},
+			{
+				name:     "A.11 ignoring unrecognized elements",
+				doc:      `{"foo": "bar"}`,
+				patch:    `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`,
+				expected: `{"foo": "bar", "baz": "qux"}`,
+			},
+			{
+				name:     "A.14 ~ escape ordering",
+				doc:      `{"/": 9, "~1": 10}`,
+				patch:    `[{"op": "test", "path": "/~01", "value": 10}]`,
+				expected: `{"/": 9, "~1": 10}`,
+			},
+			{
+				name:     "A.16 adding an array value",
+				doc:      `{"foo": ["bar"]}`,
+				patch:    `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+				expected: `{"foo": ["bar", ["abc", "def"]]}`,
+			},
+			{
+				name: "copy",
+				doc:  `{"a": {"b": [1]}}`,
+				patch: `[
+					{"op": "copy", "from": "/a", "path": "/c"},
+					{"op": "add", "path": "/c/b/-", "value": 2}
+				]`,
+				expected: `{"a": {"b": [1]}, "c": {"b": [1, 2]}}`,
+			},
+			{
+				name:     "replace the whole document",
+				doc:      `{"a": 1}`,
+				patch:    `[{"op": "replace", "path": "", "value": [null]}]`,
+				expected: `[null]`,
+			},
+			{
+				name:     "move to the same place",
+				doc:      `{"a": 1}`,
+				patch:    `[{"op": "move", "from": "/a", "path": "/a"}]`,
+				expected: `{"a": 1}`,
+			},
+		} {
+			jn := new(JSONNode)
+			err := json.Unmarshal([]byte(test.doc), jn)
+			require.NoError(t, err, test.name)
+
+			err = jn.ApplyPatch([]byte(test.patch))
+			require.NoError(t, err, test.name)
+
+			data, err := json.Marshal(jn)
+			require.NoError(t, err, test.name)
+			require.JSONEq(t, test.expected, string(data), test.name)
+		}
+	})
+
+	t.Run("rfc errors", func(t *testing.T) {
+		t.Parallel()
+
+		for _, test := range []struct {
+			name, doc, patch string
+			index            int
+		}{
+			{
+				name:  "A.9 testing a value: error",
+				doc:   `{"baz": "qux"}`,
+				patch: `[{"op": "test", "path": "/baz", "value": "bar"}]`,
+			},
+			{
+				name:  "A.12 adding to a nonexistent target",
+				doc:   `{"foo": "bar"}`,
+				patch: `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+			},
+			{
+				name:  "A.15 comparing strings and numbers",
+				doc:   `{"/": 9, "~1": 10}`,
+				patch: `[{"op": "test", "path": "/~01", "value": "10"}]`,
+			},
+			{
+				name: "remove something that isn't there",
+				doc:  `{"a": 1}`,
+				patch: `[
+					{"op": "remove", "path": "/a"},
+					{"op": "remove", "path": "/a"}
+				]`,
+				index: 1,
+			},
+			{
+				name:  "replace something that isn't there",
+				doc:   `{"a": 1}`,
+				patch: `[{"op": "replace", "path": "/b", "value": 1}]`,
+			},
+			{
+				name:  "move into itself",
+				doc:   `{"a": {"b": {}}}`,
+				patch: `[{"op": "move", "from": "/a", "path": "/a/b/c"}]`,
+			},
+			{
+				name:  "add past the end",
+				doc:   `{"a": [1]}`,
+				patch: `[{"op": "add", "path": "/a/2", "value": 3}]`,
+			},
+		} {
+			jn := new(JSONNode)
+			err := json.Unmarshal([]byte(test.doc), jn)
+			require.NoError(t, err, test.name)
+
+			err = jn.ApplyPatch([]byte(test.patch))
+			require.Error(t, err, test.name)
+
+			var patchErr *PatchError
+			require.True(t, errors.As(err, &patchErr), test.name)
+			require.Equal(t, test.index, patchErr.Index, test.name)
+
+			// Nothing changed
+			data, err := json.Marshal(jn)
+			require.NoError(t, err, test.name)
+			require.JSONEq(t, test.doc, string(data), test.name)
+		}
+	})
+
+	t.Run("invalid patches", func(t *testing.T) {
+		t.Parallel()
+
+		for _, patch := range []string{
+			`{"op": "add", "path": "/a", "value": 1}`,
+			`[{"op": "add", "path": "/a"}]`,
+			`[{"op": "move", "path": "/a"}]`,
+			`[{"path": "/a"}]`,
+			`[{"op": "add", "value": 1}]`,
+			`[{"op": "nope", "path": "/a"}]`,
+			`[1]`,
+		} {
+			_, err := ParsePatch([]byte(patch))
+			require.Error(t, err, patch)
+		}
+
+		err := New().ApplyPatch("not a patch")
+		require.Error(t, err)
+	})
+
+	t.Run("atomic", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+
+		before, err := json.Marshal(jn)
+		require.NoError(t, err)
+
+		err = jn.Get("with").ApplyPatch(Patch{
+			{Op: OpReplace, Path: "/meat", Value: "salami"},
+			{Op: OpRemove, Path: "/fruit/0"},
+			{Op: OpTest, Path: "/fruit/0/type", Value: "grapes"},
+		})
+		require.EqualError(t, err, `jsonnode: patch operation 2 (test "/fruit/0/type"): jsonnode: test failed`)
+		require.True(t, errors.Is(err, ErrTestFailed))
+
+		after, err := json.Marshal(jn)
+		require.NoError(t, err)
+		require.JSONEq(t, string(before), string(after))
+
+		// Now one that works, applied to a child
+		patch := new(JSONNode)
+		err = json.Unmarshal([]byte(`[
+			{"op": "replace", "path": "/meat", "value": "salami"},
+			{"op": "remove", "path": "/fruit/0"},
+			{"op": "test", "path": "/fruit/0/type", "value": "strawberries"}
+		]`), patch)
+		require.NoError(t, err)
+
+		err = jn.Get("with").ApplyPatch(patch)
+		require.NoError(t, err)
+
+		meat, ok := jn.Pointer("/with/meat").ValueAsString()
+		require.True(t, ok)
+		require.Equal(t, "salami", meat)
+
+		fruit, ok := jn.Pointer("/with/fruit").ValueAsSlice()
+		require.True(t, ok)
+		require.Len(t, fruit, 1)
+	})
+
+	t.Run("marshal", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := json.Marshal(Patch{
+			{Op: OpAdd, Path: "/a", Value: nil},
+			{Op: OpRemove, Path: "/b"},
+			{Op: OpMove, From: "/c", Path: "/d"},
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `[
+			{"op": "add", "path": "/a", "value": null},
+			{"op": "remove", "path": "/b"},
+			{"op": "move", "from": "/c", "path": "/d"}
+		]`, string(data))
+
+		// And back
+		patch, err := ParsePatch(data)
+		require.NoError(t, err)
+		require.Equal(t, Patch{
+			{Op: OpAdd, Path: "/a", Value: nil},
+			{Op: OpRemove, Path: "/b"},
+			{Op: OpMove, From: "/c", Path: "/d"},
+		}, patch)
+	})
+}
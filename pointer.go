@@ -0,0 +1,239 @@
+package jsonnode
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidPointer is returned when a JSON pointer is not syntactically valid.
+	ErrInvalidPointer = errors.New("jsonnode: invalid JSON pointer")
+
+	// ErrNotFound is returned when a JSON pointer does not refer to an existing value.
+	ErrNotFound = errors.New("jsonnode: not found")
+)
+
+// parsePointer splits a JSON pointer (RFC 6901) into its unescaped reference tokens.
+// The empty pointer refers to the whole document and has no tokens.
+func parsePointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+
+	if ptr[0] != '/' {
+		return nil, fmt.Errorf("%w %q: must be empty or start with '/'", ErrInvalidPointer, ptr)
+	}
+
+	tokens := strings.Split(ptr[1:], "/")
+
+	for i, token := range tokens {
+		if !strings.Contains(token, "~") {
+			continue
+		}
+
+		// Make sure there's nothing other than "~0" and "~1" in there
+		for j := 0; j < len(token); j++ {
+			if token[j] != '~' {
+				continue
+			}
+
+			if j+1 >= len(token) || (token[j+1] != '0' && token[j+1] != '1') {
+				return nil, fmt.Errorf("%w %q: '~' must be followed by '0' or '1'", ErrInvalidPointer, ptr)
+			}
+		}
+
+		// The order here matters. "~01" is "~1", not "/".
+		token = strings.Replace(token, "~1", "/", -1)
+		token = strings.Replace(token, "~0", "~", -1)
+
+		tokens[i] = token
+	}
+
+	return tokens, nil
+}
+
+// escapePointerToken escapes a field name so it can be used as a JSON pointer reference token.
+func escapePointerToken(token string) string {
+	if !strings.ContainsAny(token, "~/") {
+		return token
+	}
+
+	token = strings.Replace(token, "~", "~0", -1)
+	token = strings.Replace(token, "/", "~1", -1)
+
+	return token
+}
+
+// parseArrayIndex parses a JSON pointer reference token as an index into a JSON array.
+// Leading zeros are not allowed. The "-" token (past the end of the array) is not handled here.
+func parseArrayIndex(token string) (int, bool) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, false
+	}
+
+	for _, c := range token {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+	}
+
+	i, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, false
+	}
+
+	return i, true
+}
+
+// resolve follows the reference tokens down from this node.
+// nil is returned if any of them refer to something that doesn't exist.
+func (jn *JSONNode) resolve(tokens []string) *JSONNode {
+	node := jn
+
+	for _, token := range tokens {
+		switch node.shallowValue().(type) {
+		case *Object:
+			node = node.Get(token)
+
+		case []interface{}:
+			i, ok := parseArrayIndex(token)
+			if !ok {
+				return nil
+			}
+
+			node = node.Index(i)
+
+		default:
+			return nil
+		}
+
+		if node == nil {
+			return nil
+		}
+	}
+
+	return node
+}
+
+// Pointer gets the node referred to by the JSON pointer (RFC 6901), relative to this node.
+// For example, "/with/fruit/1/count". The empty pointer refers to this node.
+// If the pointer is invalid or the node doesn't exist, nil will be returned.
+func (jn *JSONNode) Pointer(ptr string) *JSONNode {
+	if jn == nil {
+		return nil
+	}
+
+	tokens, err := parsePointer(ptr)
+	if err != nil {
+		return nil
+	}
+
+	return jn.resolve(tokens)
+}
+
+// SetPointer sets the value referred to by the JSON pointer (RFC 6901), relative to this node, to a
+// copy of value.
+// Everything up to the last reference token must already exist.
+// If the last token refers to a JSON object member, it is added or replaced.
+// If it refers to a JSON array element, that element is replaced, or value is appended if the token is "-".
+// The empty pointer replaces the value of this node.
+func (jn *JSONNode) SetPointer(ptr string, value interface{}) error {
+	if jn == nil {
+		return ErrNilNode
+	}
+
+	tokens, err := parsePointer(ptr)
+	if err != nil {
+		return err
+	}
+
+	if len(tokens) == 0 {
+		val, err := toValue(value)
+		if err != nil {
+			return err
+		}
+
+		return jn.setValue(val)
+	}
+
+	parent := jn.resolve(tokens[:len(tokens)-1])
+	if parent == nil {
+		return fmt.Errorf("%w: %q", ErrNotFound, ptr)
+	}
+
+	last := tokens[len(tokens)-1]
+
+	switch parent.shallowValue().(type) {
+	case *Object:
+		return parent.Set(last, value)
+
+	case []interface{}:
+		if last == "-" {
+			return parent.Append(value)
+		}
+
+		i, ok := parseArrayIndex(last)
+		if !ok {
+			return fmt.Errorf("%w %q: %q is not an array index", ErrInvalidPointer, ptr, last)
+		}
+
+		node := parent.Index(i)
+		if node == nil {
+			return ErrIndexOutOfRange
+		}
+
+		val, err := toValue(value)
+		if err != nil {
+			return err
+		}
+
+		return node.setValue(val)
+
+	default:
+		return fmt.Errorf("%w: %q", ErrNotFound, ptr)
+	}
+}
+
+// DeletePointer removes the value referred to by the JSON pointer (RFC 6901), relative to this node.
+// Removing an element from a JSON array shifts any following elements down by one.
+// It is an error for the value to not exist. The empty pointer cannot be deleted.
+func (jn *JSONNode) DeletePointer(ptr string) error {
+	if jn == nil {
+		return ErrNilNode
+	}
+
+	tokens, err := parsePointer(ptr)
+	if err != nil {
+		return err
+	}
+
+	if len(tokens) == 0 {
+		return fmt.Errorf("%w %q: cannot delete the whole document", ErrInvalidPointer, ptr)
+	}
+
+	node := jn.resolve(tokens)
+	if node == nil {
+		return fmt.Errorf("%w: %q", ErrNotFound, ptr)
+	}
+
+	if node.index >= 0 {
+		return node.parent.RemoveAt(node.index)
+	}
+
+	return node.parent.Delete(node.fieldName)
+}
+
+// JSONPointer gets the JSON pointer (RFC 6901) to this node from the root of the document it is in.
+func (jn *JSONNode) JSONPointer() string {
+	if jn == nil || jn.parent == nil {
+		return ""
+	}
+
+	if jn.index >= 0 {
+		return jn.parent.JSONPointer() + "/" + strconv.Itoa(jn.index)
+	}
+
+	return jn.parent.JSONPointer() + "/" + escapePointerToken(jn.fieldName)
+}
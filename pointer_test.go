@@ -0,0 +1,211 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONNodePointer(t *testing.T) {
+	t.Parallel()
+
+	// The example document from RFC 6901, section 5
+	rfcJSON := `{
+    "foo": ["bar", "baz"],
+    "": 0,
+    "a/b": 1,
+    "c%d": 2,
+    "e^f": 3,
+    "g|h": 4,
+    "i\\j": 5,
+    "k\"l": 6,
+    " ": 7,
+    "m~n": 8
+}`
+
+	t.Run("rfc examples", func(t *testing.T) {
+		t.Parallel()
+
+		jn := new(JSONNode)
+		err := json.Unmarshal([]byte(rfcJSON), jn)
+		require.NoError(t, err)
+
+		require.Equal(t, jn.Value(), jn.Pointer("").Value())
+		require.Equal(t, []interface{}{"bar", "baz"}, jn.Pointer("/foo").Value())
+
+		for ptr, expected := range map[string]interface{}{
+			"/foo/0": "bar",
+			"/":      float64(0),
+			"/a~1b":  float64(1),
+			"/c%d":   float64(2),
+			"/e^f":   float64(3),
+			"/g|h":   float64(4),
+			"/i\\j":  float64(5),
+			"/k\"l":  float64(6),
+			"/ ":     float64(7),
+			"/m~0n":  float64(8),
+		} {
+			node := jn.Pointer(ptr)
+			require.NotNil(t, node, ptr)
+			require.Equal(t, expected, node.Value(), ptr)
+
+			// The node knows how to get back to itself
+			require.Equal(t, ptr, node.JSONPointer())
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+
+		for _, ptr := range []string{
+			"with",
+			"/nope",
+			"/cheeses/3",
+			"/cheeses/-",
+			"/cheeses/01",
+			"/cheeses/-1",
+			"/cheeses/one",
+			"/platter/slate",
+			"/with/m~2eat",
+			"/with/meat~",
+		} {
+			require.Nil(t, jn.Pointer(ptr), ptr)
+		}
+
+		var nilNode *JSONNode
+		require.Nil(t, nilNode.Pointer("/a"))
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+
+		count, ok := jn.Pointer("/with/fruit/1/count").ValueAsFloat64()
+		require.True(t, ok)
+		require.Equal(t, float64(3), count)
+
+		// Relative to a child
+		fruitType, ok := jn.Get("with").Pointer("/fruit/0/type").ValueAsString()
+		require.True(t, ok)
+		require.Equal(t, "grapes", fruitType)
+
+		require.Equal(t, "/with/fruit/0/type", jn.Get("with").Get("fruit").Index(0).Get("type").JSONPointer())
+
+		fruit, ok := jn.Pointer("/with/fruit").ValueAsSlice()
+		require.True(t, ok)
+		require.Equal(t, "/with/fruit/1", fruit[1].JSONPointer())
+	})
+
+	t.Run("set", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+
+		require.NoError(t, jn.SetPointer("/with/fruit/1/count", float64(4)))
+		require.NoError(t, jn.SetPointer("/with/fruit/-", map[string]interface{}{"type": "figs"}))
+		require.NoError(t, jn.SetPointer("/with/fruit/2/count", float64(5)))
+		require.NoError(t, jn.SetPointer("/cheeses/0", "gouda"))
+		require.NoError(t, jn.SetPointer("/with/a~1b", "slash"))
+
+		data, err := json.Marshal(jn)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+    "platter": "slate",
+    "cheeses": ["gouda", "swiss", "manchego"],
+    "with": {
+        "fruit": [
+            {"type": "grapes", "count": 8},
+            {"type": "strawberries", "count": 4},
+            {"type": "figs", "count": 5}
+        ],
+        "meat": "prosciutto",
+        "a/b": "slash"
+    }
+}`, string(data))
+
+		err = jn.SetPointer("/nope/nope", 1)
+		require.True(t, errors.Is(err, ErrNotFound), "%v", err)
+
+		err = jn.SetPointer("/cheeses/3", "edam")
+		require.Equal(t, ErrIndexOutOfRange, err)
+
+		err = jn.SetPointer("/cheeses/x", "edam")
+		require.True(t, errors.Is(err, ErrInvalidPointer), "%v", err)
+
+		err = jn.SetPointer("cheeses", "edam")
+		require.True(t, errors.Is(err, ErrInvalidPointer), "%v", err)
+
+		// The empty pointer replaces the whole thing
+		require.NoError(t, jn.SetPointer("", []interface{}{}))
+		require.Equal(t, []interface{}{}, jn.Value())
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+
+		require.NoError(t, jn.DeletePointer("/with/fruit/0"))
+		require.NoError(t, jn.DeletePointer("/cheeses/1"))
+		require.NoError(t, jn.DeletePointer("/platter"))
+
+		data, err := json.Marshal(jn)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+    "cheeses": ["cheddar", "manchego"],
+    "with": {
+        "fruit": [{"type": "strawberries", "count": 3}],
+        "meat": "prosciutto"
+    }
+}`, string(data))
+
+		err = jn.DeletePointer("/platter")
+		require.True(t, errors.Is(err, ErrNotFound), "%v", err)
+
+		err = jn.DeletePointer("")
+		require.True(t, errors.Is(err, ErrInvalidPointer), "%v", err)
+	})
+}
+
+func ExampleJSONNode_Pointer() {
+	raw := `{
+    "platter": "slate",
+    "cheeses": ["cheddar", "swiss", "manchego"],
+    "with": {
+        "fruit": [{
+                "type": "grapes",
+                "count": 8
+            },
+            {
+                "type": "strawberries",
+                "count": 3
+            }
+        ],
+        "meat": "prosciutto"
+    }
+}`
+
+	jn := new(JSONNode)
+	err := json.Unmarshal([]byte(raw), jn)
+	if err != nil {
+		panic(err)
+	}
+
+	count := jn.Pointer("/with/fruit/1/count")
+
+	countVal, ok := count.ValueAsFloat64()
+	if !ok {
+		panic(`"/with/fruit/1/count" does not exist or is not a number`)
+	}
+
+	fmt.Printf("%s: %.0f\n", count.JSONPointer(), countVal)
+
+	// Output:
+	// /with/fruit/1/count: 3
+}
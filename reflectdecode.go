@@ -0,0 +1,720 @@
+package jsonnode
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	numberType          = reflect.TypeOf(json.Number(""))
+)
+
+// Decode decodes the value of this node into v, which must be a non-nil pointer.
+// It follows the same rules as json.Unmarshal (including json struct tags, and types that implement
+// json.Unmarshaler or encoding.TextUnmarshaler), but works directly from the value held by this node
+// instead of marshalling it to JSON first.
+// A number held as a float64 no longer has its literal, so 1.0 or 1e2 can be decoded into an
+// integer, where json.Unmarshal would reject them. Numbers decoded with DecoderOptions.UseNumber,
+// or not yet decoded in a lazily decoded document, keep their literals and are checked the same way
+// json.Unmarshal checks them.
+func (jn *JSONNode) Decode(v interface{}) error {
+	return jn.DecodeWithOptions(v, DecoderOptions{})
+}
+
+// DecodeWithOptions is like Decode, as controlled by opts.
+func (jn *JSONNode) DecodeWithOptions(v interface{}, opts DecoderOptions) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &json.InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+
+	// Anything not decoded yet in a lazily decoded document is decoded from its JSON as it goes
+	value, ok := jn.lookupRaw()
+	if !ok {
+		return &PathError{Path: jn.JSONPointer(), Actual: KindMissing}
+	}
+
+	d := &valueDecoder{opts: opts}
+
+	err := d.decode(value, rv)
+	if err != nil {
+		return err
+	}
+
+	return d.savedErr
+}
+
+// valueDecoder decodes values held by a JSONNode into Go values.
+type valueDecoder struct {
+	opts DecoderOptions
+
+	// fields is the names of the fields and map keys being decoded into, for errors
+	fields []string
+
+	// savedErr is the first error that decoding carried on after, like encoding/json does for values
+	// that are the wrong type
+	savedErr error
+}
+
+func (d *valueDecoder) saveError(err error) {
+	if d.savedErr == nil {
+		d.savedErr = err
+	}
+}
+
+func (d *valueDecoder) saveTypeError(value interface{}, t reflect.Type) {
+	desc := kindOf(value).String()
+	if kindOf(value) == KindNumber {
+		desc += " " + fmt.Sprint(value)
+	}
+
+	d.saveError(&json.UnmarshalTypeError{Value: desc, Type: t, Field: strings.Join(d.fields, ".")})
+}
+
+// decode decodes value into v.
+// An error is only returned if decoding has to stop. Other errors are saved.
+func (d *valueDecoder) decode(value interface{}, v reflect.Value) error {
+	raw := value
+	value = expandLiteral(value)
+
+	u, tu, v := indirect(v, value == nil)
+	if u != nil {
+		return d.unmarshaler(u, raw)
+	}
+
+	if tu != nil {
+		s, ok := value.(string)
+		if !ok {
+			d.saveTypeError(value, reflect.TypeOf(tu))
+			return nil
+		}
+
+		return tu.UnmarshalText([]byte(s))
+	}
+
+	switch val := value.(type) {
+	case nil:
+		switch v.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+		}
+
+	case bool:
+		switch {
+		case v.Kind() == reflect.Bool:
+			v.SetBool(val)
+
+		case v.Kind() == reflect.Interface && v.NumMethod() == 0:
+			v.Set(reflect.ValueOf(val))
+
+		default:
+			d.saveTypeError(value, v.Type())
+		}
+
+	case string:
+		d.string(val, v)
+
+	case float64, json.Number:
+		d.number(val, v)
+
+	case []interface{}:
+		return d.array(val, v)
+
+	case *Object:
+		return d.object(val, v)
+
+	default:
+		// Not one of the types used to represent JSON, so let encoding/json deal with it
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		ptr := reflect.New(v.Type())
+
+		err = json.Unmarshal(data, ptr.Interface())
+		if err != nil {
+			return err
+		}
+
+		v.Set(ptr.Elem())
+	}
+
+	return nil
+}
+
+// unmarshaler decodes value with a json.Unmarshaler.
+func (d *valueDecoder) unmarshaler(u json.Unmarshaler, value interface{}) error {
+	// These don't need to go through JSON
+	switch u := u.(type) {
+	case *JSONNode:
+		u.init()
+		u.data = materialize(copyValue(value))
+
+		return nil
+
+	case *Object:
+		obj, ok := materialize(copyValue(value)).(*Object)
+		if !ok {
+			d.saveTypeError(expandLiteral(value), reflect.TypeOf(u))
+			return nil
+		}
+		u.keys, u.values = obj.keys, obj.values
+		u.invalidate()
+
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return u.UnmarshalJSON(data)
+}
+
+func (d *valueDecoder) string(s string, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.Type() == numberType && !isNumber(s) {
+			d.saveTypeError(s, v.Type())
+			return
+		}
+
+		v.SetString(s)
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			d.saveTypeError(s, v.Type())
+			return
+		}
+
+		// []byte is base64 encoded
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			d.saveError(err)
+			return
+		}
+
+		v.SetBytes(b)
+
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			d.saveTypeError(s, v.Type())
+			return
+		}
+
+		v.Set(reflect.ValueOf(s))
+
+	default:
+		d.saveTypeError(s, v.Type())
+	}
+}
+
+func (d *valueDecoder) number(n interface{}, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			d.saveTypeError(n, v.Type())
+			return
+		}
+
+		v.Set(reflect.ValueOf(d.interfaceValue(n)))
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := numberInt64(n)
+		if !ok || v.OverflowInt(i) {
+			d.saveTypeError(n, v.Type())
+			return
+		}
+
+		v.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, ok := numberUint64(n)
+		if !ok || v.OverflowUint(u) {
+			d.saveTypeError(n, v.Type())
+			return
+		}
+
+		v.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, ok := numberFloat64(n)
+		if !ok || v.OverflowFloat(f) {
+			d.saveTypeError(n, v.Type())
+			return
+		}
+
+		v.SetFloat(f)
+
+	case reflect.String:
+		if v.Type() != numberType {
+			d.saveTypeError(n, v.Type())
+			return
+		}
+
+		v.SetString(string(numberLiteral(n)))
+
+	default:
+		d.saveTypeError(n, v.Type())
+	}
+}
+
+func (d *valueDecoder) array(arr []interface{}, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			d.saveTypeError(arr, v.Type())
+			return nil
+		}
+
+		v.Set(reflect.ValueOf(d.interfaceValue(arr)))
+
+		return nil
+
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if i >= len(arr) {
+				v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+				continue
+			}
+
+			err := d.decode(arr[i], v.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	case reflect.Slice:
+		if len(arr) == 0 {
+			v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+			return nil
+		}
+
+		if v.Cap() < len(arr) {
+			newSlice := reflect.MakeSlice(v.Type(), len(arr), len(arr))
+			reflect.Copy(newSlice, v)
+			v.Set(newSlice)
+		} else {
+			oldLen := v.Len()
+			v.SetLen(len(arr))
+
+			for i := oldLen; i < len(arr); i++ {
+				v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+			}
+		}
+
+		for i := range arr {
+			err := d.decode(arr[i], v.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	d.saveTypeError(arr, v.Type())
+
+	return nil
+}
+
+func (d *valueDecoder) object(obj *Object, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			d.saveTypeError(obj, v.Type())
+			return nil
+		}
+
+		v.Set(reflect.ValueOf(d.interfaceValue(obj)))
+
+		return nil
+
+	case reflect.Map:
+		return d.objectToMap(obj, v)
+
+	case reflect.Struct:
+		return d.objectToStruct(obj, v)
+	}
+
+	d.saveTypeError(obj, v.Type())
+
+	return nil
+}
+
+func (d *valueDecoder) objectToMap(obj *Object, v reflect.Value) error {
+	keyType := v.Type().Key()
+
+	switch keyType.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+
+	default:
+		if !reflect.PtrTo(keyType).Implements(textUnmarshalerType) {
+			d.saveTypeError(obj, v.Type())
+			return nil
+		}
+	}
+
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(v.Type(), obj.Len()))
+	}
+
+	elemType := v.Type().Elem()
+
+	for _, key := range obj.keys {
+		elem := reflect.New(elemType).Elem()
+
+		d.fields = append(d.fields, key)
+		err := d.decode(obj.values[key], elem)
+		d.fields = d.fields[:len(d.fields)-1]
+
+		if err != nil {
+			return err
+		}
+
+		var keyValue reflect.Value
+
+		switch {
+		case reflect.PtrTo(keyType).Implements(textUnmarshalerType):
+			keyValue = reflect.New(keyType)
+
+			err = keyValue.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key))
+			if err != nil {
+				return err
+			}
+
+			keyValue = keyValue.Elem()
+
+		case keyType.Kind() == reflect.String:
+			keyValue = reflect.ValueOf(key).Convert(keyType)
+
+		default:
+			keyValue = reflect.New(keyType).Elem()
+
+			switch keyType.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				i, err := strconv.ParseInt(key, 10, 64)
+				if err != nil || keyValue.OverflowInt(i) {
+					d.saveError(&json.UnmarshalTypeError{Value: "number " + key, Type: keyType})
+					continue
+				}
+
+				keyValue.SetInt(i)
+
+			default:
+				u, err := strconv.ParseUint(key, 10, 64)
+				if err != nil || keyValue.OverflowUint(u) {
+					d.saveError(&json.UnmarshalTypeError{Value: "number " + key, Type: keyType})
+					continue
+				}
+
+				keyValue.SetUint(u)
+			}
+		}
+
+		v.SetMapIndex(keyValue, elem)
+	}
+
+	return nil
+}
+
+func (d *valueDecoder) objectToStruct(obj *Object, v reflect.Value) error {
+	fields := cachedTypeFields(v.Type())
+
+	for _, key := range obj.keys {
+		f := fields.find(key)
+		if f == nil {
+			if d.opts.DisallowUnknownFields {
+				d.saveError(fmt.Errorf("jsonnode: unknown field %q", key))
+			}
+
+			continue
+		}
+
+		fieldValue, err := fieldByIndex(v, f.index)
+		if err != nil {
+			d.saveError(err)
+			continue
+		}
+
+		d.fields = append(d.fields, f.name)
+
+		if f.quoted {
+			err = d.quoted(obj.values[key], fieldValue)
+		} else {
+			err = d.decode(obj.values[key], fieldValue)
+		}
+
+		d.fields = d.fields[:len(d.fields)-1]
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// quoted decodes a value for a field with the ",string" tag option, which holds its value as JSON
+// inside a JSON string.
+func (d *valueDecoder) quoted(value interface{}, v reflect.Value) error {
+	value = expandLiteral(value)
+
+	s, ok := value.(string)
+	if !ok {
+		if value == nil {
+			return d.decode(nil, v)
+		}
+
+		d.saveError(fmt.Errorf("jsonnode: invalid use of ,string struct tag, trying to decode %s into %v", kindOf(value), v.Type()))
+
+		return nil
+	}
+
+	inner, err := decode([]byte(s), DecoderOptions{UseNumber: true})
+
+	if err == nil {
+		switch inner.(type) {
+		case string:
+			ok = v.Kind() == reflect.String
+
+		case nil, bool, json.Number:
+			ok = v.Kind() != reflect.String
+		}
+	}
+
+	if err != nil || !ok {
+		d.saveError(fmt.Errorf("jsonnode: invalid use of ,string struct tag, trying to decode %q into %v", s, v.Type()))
+		return nil
+	}
+
+	return d.decode(inner, v)
+}
+
+// interfaceValue converts value into what encoding/json would unmarshal it into for an interface{}.
+func (d *valueDecoder) interfaceValue(value interface{}) interface{} {
+	value = expandLiteral(value)
+
+	switch val := value.(type) {
+	case *Object:
+		m := make(map[string]interface{}, len(val.keys))
+		for _, key := range val.keys {
+			m[key] = d.interfaceValue(val.values[key])
+		}
+
+		return m
+
+	case []interface{}:
+		arr := make([]interface{}, len(val))
+		for i := range val {
+			arr[i] = d.interfaceValue(val[i])
+		}
+
+		return arr
+
+	case float64, json.Number:
+		if d.opts.UseNumber {
+			return numberLiteral(val)
+		}
+
+		f, ok := numberFloat64(val)
+		if !ok {
+			d.saveTypeError(val, reflect.TypeOf(f))
+		}
+
+		return f
+	}
+
+	return value
+}
+
+// fieldByIndex gets a (possibly embedded) struct field, allocating any nil embedded struct pointers
+// along the way.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for _, i := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("jsonnode: cannot set embedded pointer to unexported struct: %v", v.Type().Elem())
+				}
+
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(i)
+	}
+
+	return v, nil
+}
+
+// indirect walks down v, allocating nil pointers as needed, until it gets to something that isn't a
+// pointer. If it finds a json.Unmarshaler or encoding.TextUnmarshaler along the way, it stops and
+// returns that. If decodingNull is true, it stops at the last pointer so that it can be set to nil.
+// This is the same as what encoding/json does.
+func indirect(v reflect.Value, decodingNull bool) (json.Unmarshaler, encoding.TextUnmarshaler, reflect.Value) {
+	v0 := v
+	haveAddr := false
+
+	// If v is a named type and is addressable, start with its address, so that if the type has
+	// pointer methods, they are found
+	if v.Kind() != reflect.Ptr && v.Type().Name() != "" && v.CanAddr() {
+		haveAddr = true
+		v = v.Addr()
+	}
+
+	for {
+		// Use the value in an interface, but only if it is a pointer that can be decoded into
+		if v.Kind() == reflect.Interface && !v.IsNil() {
+			e := v.Elem()
+			if e.Kind() == reflect.Ptr && !e.IsNil() && (!decodingNull || e.Elem().Kind() == reflect.Ptr) {
+				haveAddr = false
+				v = e
+
+				continue
+			}
+		}
+
+		if v.Kind() != reflect.Ptr {
+			break
+		}
+
+		if decodingNull && v.CanSet() {
+			break
+		}
+
+		// Don't loop forever if v is an interface holding a pointer to itself
+		if v.Elem().Kind() == reflect.Interface && v.Elem().Elem() == v {
+			v = v.Elem()
+			break
+		}
+
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		if v.Type().NumMethod() > 0 && v.CanInterface() {
+			if u, ok := v.Interface().(json.Unmarshaler); ok {
+				return u, nil, reflect.Value{}
+			}
+
+			if !decodingNull {
+				if u, ok := v.Interface().(encoding.TextUnmarshaler); ok {
+					return nil, u, reflect.Value{}
+				}
+			}
+		}
+
+		if haveAddr {
+			// Back to the original value, rather than v.Addr().Elem()
+			v = v0
+			haveAddr = false
+		} else {
+			v = v.Elem()
+		}
+	}
+
+	return nil, nil, v
+}
+
+// expandLiteral decodes value if it is a *rawValue, leaving anything under it to be decoded later.
+// A number is decoded as a json.Number, so that its literal is kept.
+func expandLiteral(value interface{}) interface{} {
+	raw, ok := value.(*rawValue)
+	if !ok {
+		return value
+	}
+
+	value = raw.decodeShallow()
+	if kindOf(value) == KindNumber {
+		return json.Number(raw.data)
+	}
+
+	return value
+}
+
+// numberInt64 converts a number (float64 or json.Number) into an int64, if it can be done exactly.
+func numberInt64(n interface{}) (int64, bool) {
+	switch n := n.(type) {
+	case float64:
+		if n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+			return 0, false
+		}
+
+		return int64(n), true
+
+	case json.Number:
+		i, err := strconv.ParseInt(string(n), 10, 64)
+		return i, err == nil
+	}
+
+	return 0, false
+}
+
+// numberUint64 converts a number (float64 or json.Number) into a uint64, if it can be done exactly.
+func numberUint64(n interface{}) (uint64, bool) {
+	switch n := n.(type) {
+	case float64:
+		if n != math.Trunc(n) || n < 0 || n >= math.MaxUint64 {
+			return 0, false
+		}
+
+		return uint64(n), true
+
+	case json.Number:
+		u, err := strconv.ParseUint(string(n), 10, 64)
+		return u, err == nil
+	}
+
+	return 0, false
+}
+
+// numberFloat64 converts a number (float64 or json.Number) into a float64.
+func numberFloat64(n interface{}) (float64, bool) {
+	switch n := n.(type) {
+	case float64:
+		return n, true
+
+	case json.Number:
+		f, err := strconv.ParseFloat(string(n), 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+// numberLiteral converts a number (float64 or json.Number) into a json.Number.
+func numberLiteral(n interface{}) json.Number {
+	if f, ok := n.(float64); ok {
+		return json.Number(formatFloat(f))
+	}
+
+	return n.(json.Number)
+}
+
+// isNumber reports whether s is a valid JSON number.
+func isNumber(s string) bool {
+	value, err := decode([]byte(s), DecoderOptions{UseNumber: true})
+	if err != nil {
+		return false
+	}
+
+	_, ok := value.(json.Number)
+
+	return ok
+}
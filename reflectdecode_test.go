@@ -0,0 +1,299 @@
+package jsonnode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// upperKey upper-cases itself when unmarshalled from text.
+type upperKey string
+
+func (k *upperKey) UnmarshalText(text []byte) error {
+	*k = upperKey(strings.ToUpper(string(text)))
+	return nil
+}
+
+type decodeInner struct {
+	Name string `json:"name"`
+}
+
+type DecodePointer struct {
+	Name string `json:"name"`
+}
+
+type decodeEmbedded struct {
+	Embedded string
+	Shadowed string
+}
+
+type decodeTarget struct {
+	decodeEmbedded
+	*DecodePointer
+
+	Shadowed   int                 `json:"Shadowed"`
+	Renamed    string              `json:"renamed,omitempty"`
+	Skipped    string              `json:"-"`
+	Dash       string              `json:"-,"`
+	Quoted     int64               `json:"quoted,string"`
+	QuotedStr  string              `json:",string"`
+	Ptr        *float64            `json:"ptr"`
+	Any        interface{}         `json:"any"`
+	Slice      []decodeInner       `json:"slice"`
+	Array      [2]int              `json:"array"`
+	Map        map[string]int      `json:"map"`
+	IntMap     map[int]string      `json:"int_map"`
+	Bytes      []byte              `json:"bytes"`
+	Time       time.Time           `json:"time"`
+	IP         net.IP              `json:"ip"`
+	Number     json.Number         `json:"number"`
+	Node       *JSONNode           `json:"node"`
+	Raw        json.RawMessage     `json:"raw"`
+	TextMap    map[upperKey]string `json:"text_map"`
+	unexported string
+}
+
+func TestJSONNodeDecode(t *testing.T) {
+	t.Parallel()
+
+	const input = `{
+		"Embedded": "promoted",
+		"Shadowed": 3,
+		"name": "pointer to embedded",
+		"RENAMED": "case insensitive",
+		"Skipped": "no",
+		"-": "dash",
+		"quoted": "9007199254740993",
+		"QuotedStr": "\"inner\"",
+		"ptr": 1.5,
+		"any": {"a": [1, "two", null, true]},
+		"slice": [{"name": "a"}, {"name": "b"}],
+		"array": [1, 2, 3],
+		"map": {"x": 1, "y": 2},
+		"int_map": {"1": "one", "-2": "minus two"},
+		"bytes": "aGVsbG8=",
+		"time": "2021-02-03T04:05:06Z",
+		"ip": "192.0.2.1",
+		"number": 1.25,
+		"node": {"b": 1, "a": 2},
+		"raw": [1, {"x": null}],
+		"text_map": {"key": "value"},
+		"unexported": "ignored"
+	}`
+
+	jn := new(JSONNode)
+	require.NoError(t, json.Unmarshal([]byte(input), jn))
+
+	var expected, actual decodeTarget
+	require.NoError(t, json.Unmarshal([]byte(input), &expected))
+	require.NoError(t, jn.Decode(&actual))
+
+	// The nodes aren't comparable, and the raw JSON is formatted differently
+	require.Equal(t, `{"b":1,"a":2}`, marshal(t, actual.Node))
+	require.Equal(t, `[1,{"x":null}]`, string(actual.Raw))
+	expected.Node, actual.Node = nil, nil
+	expected.Raw, actual.Raw = nil, nil
+
+	require.Equal(t, expected, actual)
+	require.Equal(t, int64(9007199254740993), actual.Quoted)
+	require.Equal(t, "inner", actual.QuotedStr)
+	require.Equal(t, "promoted", actual.Embedded)
+	require.Equal(t, "", actual.decodeEmbedded.Shadowed)
+	require.Equal(t, "pointer to embedded", actual.DecodePointer.Name)
+	require.Equal(t, map[upperKey]string{"KEY": "value"}, actual.TextMap)
+}
+
+func TestJSONNodeDecodeMatchesUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	targets := []func() interface{}{
+		func() interface{} { return new(interface{}) },
+		func() interface{} { return new(string) },
+		func() interface{} { return new(int8) },
+		func() interface{} { return new(uint) },
+		func() interface{} { return new(float32) },
+		func() interface{} { return new(bool) },
+		func() interface{} { return new(*int) },
+		func() interface{} { return new([]int) },
+		func() interface{} { return new([]interface{}) },
+		func() interface{} { return new(map[string]interface{}) },
+		func() interface{} { return new(map[string]*decodeInner) },
+		func() interface{} { return new(decodeInner) },
+		func() interface{} { return new(json.Number) },
+		func() interface{} { return new(fmtStringer) },
+	}
+
+	inputs := []string{
+		`null`,
+		`true`,
+		`"str"`,
+		`"123"`,
+		`12`,
+		`-1`,
+		`300`,
+		`1.5`,
+		`1.0`,
+		`1e2`,
+		`1e400`,
+		`1234567`,
+		`100000000`,
+		`[1e21, 0.0000001]`,
+		`[]`,
+		`[1, 2, "3"]`,
+		`[1.0, 2]`,
+		`{}`,
+		`{"name": "x", "other": 1}`,
+		`{"a": {"name": 5}, "b": null}`,
+		`{"id": 1234567, "big": 100000000}`,
+	}
+
+	// A float64 doesn't keep its literal, so these can't be rejected for integers, or decoded into a
+	// json.Number as they were
+	literals := map[string]bool{`1.0`: true, `1e2`: true, `[1.0, 2]`: true, `[1e21, 0.0000001]`: true}
+
+	for _, input := range inputs {
+		for _, opts := range []DecoderOptions{{}, {UseNumber: true}, {Lazy: true}, {Lazy: true, UseNumber: true}} {
+			useNumber := opts.UseNumber
+
+			jn, err := Unmarshal([]byte(input), opts)
+			if !useNumber && !opts.Lazy && input == `1e400` {
+				// Too big for a float64
+				require.Error(t, err)
+				continue
+			}
+
+			if !useNumber && !opts.Lazy && literals[input] {
+				continue
+			}
+
+			require.NoError(t, err)
+
+			for _, target := range targets {
+				expected, actual := target(), target()
+
+				dec := json.NewDecoder(strings.NewReader(input))
+				if useNumber {
+					dec.UseNumber()
+				}
+
+				expectedErr := dec.Decode(expected)
+				actualErr := jn.DecodeWithOptions(actual, DecoderOptions{UseNumber: useNumber})
+
+				name := input + " into " + reflect.TypeOf(expected).Elem().String()
+				if useNumber {
+					name += " using numbers"
+				}
+
+				if opts.Lazy {
+					name += " lazily"
+				}
+
+				require.Equal(t, expectedErr != nil, actualErr != nil, "%s: %v, %v", name, expectedErr, actualErr)
+
+				var typeErr *json.UnmarshalTypeError
+				if errors.As(expectedErr, &typeErr) {
+					require.True(t, errors.As(actualErr, &typeErr), "%s: %v", name, actualErr)
+				}
+
+				if expectedErr == nil {
+					// What's left after an error isn't always the same (encoding/json sets a float32 to +Inf
+					// when the number is too big, for example)
+					require.Equal(t, expected, actual, name)
+				}
+			}
+		}
+	}
+}
+
+type fmtStringer interface {
+	String() string
+}
+
+func TestJSONNodeDecodeOptions(t *testing.T) {
+	t.Parallel()
+
+	jn := new(JSONNode)
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "x", "extra": 1, "n": 2}`), jn))
+
+	var inner decodeInner
+	require.NoError(t, jn.Decode(&inner))
+	require.Equal(t, "x", inner.Name)
+
+	err := jn.DecodeWithOptions(&inner, DecoderOptions{DisallowUnknownFields: true})
+	require.EqualError(t, err, `jsonnode: unknown field "extra"`)
+
+	var m map[string]interface{}
+	require.NoError(t, jn.DecodeWithOptions(&m, DecoderOptions{UseNumber: true}))
+	require.Equal(t, json.Number("2"), m["n"])
+
+	// Numbers held as float64 are given as json.Number the way json.Unmarshal gives them
+	data := []byte(`{"id": 1234567, "big": 100000000, "small": 0.5}`)
+	jn = unmarshalString(t, string(data))
+
+	var expected map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	require.NoError(t, dec.Decode(&expected))
+
+	m = nil
+	require.NoError(t, jn.DecodeWithOptions(&m, DecoderOptions{UseNumber: true}))
+	require.Equal(t, expected, m)
+
+	var id json.Number
+	require.NoError(t, jn.Get("id").Decode(&id))
+	require.Equal(t, json.Number("1234567"), id)
+
+	// The first type error is returned, but the rest is still decoded
+	var fruits []fruitCount
+	jn = new(JSONNode)
+	require.NoError(t, json.Unmarshal([]byte(`[{"count": "one"}, {"count": 2}]`), jn))
+
+	err = jn.Decode(&fruits)
+	require.Error(t, err)
+	require.Equal(t, []fruitCount{{}, {Count: 2}}, fruits)
+
+	var typeErr *json.UnmarshalTypeError
+	require.True(t, errors.As(err, &typeErr))
+	require.Equal(t, "count", typeErr.Field)
+
+	require.Error(t, jn.Decode(fruits))
+	require.Error(t, jn.Decode(nil))
+
+	var missing *JSONNode
+	err = missing.Decode(&fruits)
+	require.True(t, errors.Is(err, ErrNotFound))
+}
+
+type fruitCount struct {
+	Count int `json:"count"`
+}
+
+func BenchmarkDecode(b *testing.B) {
+	jn := new(JSONNode)
+	require.NoError(b, json.Unmarshal([]byte(platterJSON), jn))
+
+	fruit := jn.Get("with").Get("fruit")
+
+	b.Run("Decode", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var fruits []fruitCount
+			_ = fruit.Decode(&fruits)
+		}
+	})
+
+	b.Run("MarshalJSON and Unmarshal", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var fruits []fruitCount
+
+			data, _ := fruit.MarshalJSON()
+			_ = json.Unmarshal(data, &fruits)
+		}
+	})
+}
@@ -0,0 +1,657 @@
+package jsonnode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Syntax is a flavor of JSON that can be decoded.
+type Syntax int
+
+// The syntaxes that can be decoded.
+const (
+	// SyntaxJSON is standard JSON (RFC 8259).
+	SyntaxJSON Syntax = iota
+
+	// SyntaxJSONC is JSON with comments (// and /* */) and trailing commas in objects and arrays.
+	SyntaxJSONC
+
+	// SyntaxJSON5 is JSON5 (https://spec.json5.org): JSONC, plus unquoted member names,
+	// single-quoted strings, more escapes in strings, hexadecimal numbers, numbers with a leading
+	// '+' or a leading or trailing decimal point, Infinity, and NaN.
+	// Infinity and NaN can't be represented in standard JSON, so they are converted to null, or are
+	// an error if StandardizeOptions.RejectNonFinite is set.
+	SyntaxJSON5
+)
+
+func (s Syntax) String() string {
+	switch s {
+	case SyntaxJSON:
+		return "JSON"
+
+	case SyntaxJSONC:
+		return "JSONC"
+
+	case SyntaxJSON5:
+		return "JSON5"
+	}
+
+	return "unknown"
+}
+
+// SyntaxError is returned when JSONC or JSON5 isn't valid. It gives the position of the error in
+// the JSONC or JSON5, rather than in the standard JSON it is converted to.
+type SyntaxError struct {
+	// Offset is the byte offset of the error, starting at 0.
+	Offset int
+
+	// Line and Column are the position of the error, starting at 1. Column counts bytes.
+	Line, Column int
+
+	// Err describes the error. It is a *json.SyntaxError if the error was found when decoding the
+	// standard JSON, in which case its Offset is in that.
+	Err error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("jsonnode: line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns Err.
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// StandardizeOptions controls how StandardizeWithOptions converts JSONC or JSON5.
+type StandardizeOptions struct {
+	// RejectNonFinite makes JSON5's Infinity, -Infinity, and NaN an error, rather than them being
+	// converted to null, since standard JSON can't represent them.
+	RejectNonFinite bool
+}
+
+// Standardize converts data from syntax to standard JSON, dropping any comments and whitespace.
+// Only what syntax allows beyond standard JSON is converted, so the result may still not be valid
+// JSON; decoding it will say why, though at an offset in the result rather than in data. Unmarshal
+// with DecoderOptions.Syntax gives the offset in data instead.
+// An error converting data is a *SyntaxError.
+func Standardize(data []byte, syntax Syntax) ([]byte, error) {
+	return StandardizeWithOptions(data, syntax, StandardizeOptions{})
+}
+
+// StandardizeWithOptions is like Standardize, with data converted as controlled by opts.
+func StandardizeWithOptions(data []byte, syntax Syntax, opts StandardizeOptions) ([]byte, error) {
+	if syntax == SyntaxJSON {
+		return data, nil
+	}
+
+	s, err := standardize(data, syntax, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.out, nil
+}
+
+func standardize(data []byte, syntax Syntax, opts StandardizeOptions) (*standardizer, error) {
+	s := &standardizer{data: data, json5: syntax == SyntaxJSON5, opts: opts, out: make([]byte, 0, len(data))}
+
+	err := s.run()
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// standardizer converts JSONC or JSON5 to standard JSON, a token at a time.
+type standardizer struct {
+	data  []byte
+	i     int
+	json5 bool
+	opts  StandardizeOptions
+	out   []byte
+
+	// space is set if there was whitespace or a comment before the next token
+	space bool
+
+	// comma is set if there was a comma before the next token, which hasn't been written yet in
+	// case it is a trailing comma. commaAt is where it is in data.
+	comma   bool
+	commaAt int
+
+	// marks map offsets in out back to data. Each is where something converted from data starts;
+	// anything after it up to the next mark is copied from data as it was.
+	marks []offsetMark
+}
+
+// offsetMark maps an offset in the standardized output to one in the input.
+type offsetMark struct {
+	out, in int
+}
+
+func (s *standardizer) run() error {
+	for {
+		err := s.skipSpace()
+		if err != nil {
+			return err
+		}
+
+		if s.i >= len(s.data) {
+			if s.comma {
+				// Let the JSON decoder report it
+				s.mark(s.commaAt)
+				s.write(",")
+			}
+
+			return nil
+		}
+
+		c := s.data[s.i]
+
+		if c == ',' {
+			if s.comma {
+				// Let the JSON decoder report it
+				s.mark(s.commaAt)
+				s.write(",")
+			}
+
+			s.commaAt = s.i
+			s.i++
+			s.comma = true
+
+			continue
+		}
+
+		s.writeSeparator(c)
+		s.mark(s.i)
+
+		switch {
+		case c == '"' || (c == '\'' && s.json5):
+			if !s.json5 {
+				start := s.i
+				s.i = skipString(s.data, s.i)
+				s.out = append(s.out, s.data[start:s.i]...)
+
+				continue
+			}
+
+			err = s.string()
+
+		case !s.json5:
+			s.out = append(s.out, c)
+			s.i++
+
+		case c == '+' || c == '-' || c == '.' || ('0' <= c && c <= '9'):
+			err = s.number()
+
+		case s.isIdentifierStart():
+			err = s.identifier()
+
+		default:
+			s.out = append(s.out, c)
+			s.i++
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// writeSeparator writes any comma and whitespace that came before the next token, which starts
+// with c. A comma is dropped if the token closes an object or array, unless the comma follows
+// another comma or the opening '{' or '[', in which case it is kept for the JSON decoder to reject.
+func (s *standardizer) writeSeparator(c byte) {
+	if s.comma {
+		if (c != '}' && c != ']') || s.lastIs('{', '[', ',') {
+			s.mark(s.commaAt)
+			s.write(",")
+		}
+
+		s.comma = false
+	}
+
+	if s.space {
+		// Keep tokens apart, so that "1 2" doesn't become "12"
+		if !s.lastIs('{', '[', ',', ':', '"', '}', ']') && bytes.IndexByte([]byte(`{}[],:"'/`), c) < 0 {
+			s.write(" ")
+		}
+
+		s.space = false
+	}
+}
+
+// lastIs reports whether the last byte written is one of chars, or nothing has been written.
+func (s *standardizer) lastIs(chars ...byte) bool {
+	return len(s.out) == 0 || bytes.IndexByte(chars, s.out[len(s.out)-1]) >= 0
+}
+
+// writeChar writes a byte of a string. Tabs and other control characters, which can be in a JSON5
+// string but not a JSON one, are escaped. Line breaks aren't allowed in either, so they are left
+// for the JSON decoder to reject.
+func (s *standardizer) writeChar(c byte) {
+	if c < ' ' && c != '\n' && c != '\r' {
+		s.out = append(s.out, fmt.Sprintf(`\u%04x`, c)...)
+		s.mark(s.i)
+
+		return
+	}
+
+	s.out = append(s.out, c)
+}
+
+func (s *standardizer) write(str string) {
+	s.out = append(s.out, str...)
+}
+
+// mark records that what is written next comes from offset in in data.
+func (s *standardizer) mark(in int) {
+	if n := len(s.marks); n > 0 && s.marks[n-1].out == len(s.out) {
+		s.marks[n-1].in = in
+		return
+	}
+
+	s.marks = append(s.marks, offsetMark{out: len(s.out), in: in})
+}
+
+// inputOffset translates an offset in out to one in data.
+func (s *standardizer) inputOffset(out int) int {
+	// The last mark at or before out
+	i := sort.Search(len(s.marks), func(i int) bool { return s.marks[i].out > out }) - 1
+	if i < 0 {
+		return out
+	}
+
+	in := s.marks[i].in + out - s.marks[i].out
+
+	// Something converted may be longer than it was in data
+	if i+1 < len(s.marks) && in > s.marks[i+1].in {
+		in = s.marks[i+1].in
+	}
+
+	if in > len(s.data) {
+		in = len(s.data)
+	}
+
+	return in
+}
+
+// syntaxError creates a *SyntaxError for an error at offset in data.
+func (s *standardizer) syntaxError(offset int, err error) *SyntaxError {
+	line := 1 + bytes.Count(s.data[:offset], []byte("\n"))
+	column := 1 + offset - (bytes.LastIndexByte(s.data[:offset], '\n') + 1)
+
+	return &SyntaxError{Offset: offset, Line: line, Column: column, Err: err}
+}
+
+// translateError translates an error decoding out into a *SyntaxError in data. Other errors are
+// returned as they are.
+func (s *standardizer) translateError(err error) error {
+	var syntaxErr *json.SyntaxError
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		// The offset is just after the byte that is in error
+		offset := int(syntaxErr.Offset) - 1
+		if offset < 0 {
+			offset = 0
+		}
+
+		return s.syntaxError(s.inputOffset(offset), syntaxErr)
+
+	case err == io.ErrUnexpectedEOF:
+		return s.syntaxError(len(s.data), err)
+	}
+
+	return err
+}
+
+func (s *standardizer) errorf(format string, args ...interface{}) error {
+	return s.syntaxError(s.i, fmt.Errorf(format, args...))
+}
+
+// skipSpace skips whitespace and comments.
+func (s *standardizer) skipSpace() error {
+	for s.i < len(s.data) {
+		c := s.data[s.i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			s.i++
+
+		case c == '/' && s.i+1 < len(s.data) && s.data[s.i+1] == '/':
+			end := bytes.IndexByte(s.data[s.i:], '\n')
+			if end < 0 {
+				s.i = len(s.data)
+			} else {
+				s.i += end + 1
+			}
+
+		case c == '/' && s.i+1 < len(s.data) && s.data[s.i+1] == '*':
+			end := bytes.Index(s.data[s.i+2:], []byte("*/"))
+			if end < 0 {
+				return s.errorf("unterminated comment")
+			}
+
+			s.i += end + 4
+
+		case s.json5 && (c == '\v' || c == '\f'):
+			s.i++
+
+		case s.json5 && c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRune(s.data[s.i:])
+			if r != '\uFEFF' && !unicode.Is(unicode.Zs, r) && r != '\u2028' && r != '\u2029' {
+				return nil
+			}
+
+			s.i += size
+
+		default:
+			return nil
+		}
+
+		s.space = true
+	}
+
+	return nil
+}
+
+// string converts a JSON5 string, in double or single quotes.
+func (s *standardizer) string() error {
+	quote := s.data[s.i]
+	s.i++
+
+	s.write(`"`)
+
+	for s.i < len(s.data) {
+		c := s.data[s.i]
+		s.i++
+
+		switch c {
+		case quote:
+			s.write(`"`)
+			return nil
+
+		case '"':
+			s.write(`\"`)
+
+		case '\\':
+			if s.i >= len(s.data) {
+				return s.errorf("unterminated string")
+			}
+
+			s.mark(s.i - 1)
+
+			err := s.escape()
+			if err != nil {
+				return err
+			}
+
+			s.mark(s.i)
+
+		default:
+			s.writeChar(c)
+		}
+	}
+
+	return s.errorf("unterminated string")
+}
+
+// escape converts the escape sequence after a '\' in a JSON5 string.
+func (s *standardizer) escape() error {
+	// Errors are reported at the '\\'
+	start := s.i - 1
+
+	c := s.data[s.i]
+	s.i++
+
+	switch c {
+	case '"', '\\', '/', 'b', 'f', 'n', 'r', 't', 'u':
+		// The same as in JSON
+		s.out = append(s.out, '\\', c)
+
+	case 'v':
+		s.write(`\u000b`)
+
+	case '0':
+		if s.i < len(s.data) && '0' <= s.data[s.i] && s.data[s.i] <= '9' {
+			s.i = start
+			return s.errorf("invalid escape sequence")
+		}
+
+		s.write(`\u0000`)
+
+	case 'x':
+		if s.i+2 > len(s.data) || !isHex(s.data[s.i]) || !isHex(s.data[s.i+1]) {
+			s.i = start
+			return s.errorf("invalid escape sequence")
+		}
+
+		s.write(`\u00`)
+		s.out = append(s.out, s.data[s.i:s.i+2]...)
+		s.i += 2
+
+	case '\r':
+		// A line continuation
+		if s.i < len(s.data) && s.data[s.i] == '\n' {
+			s.i++
+		}
+
+	case '\n':
+		// A line continuation
+
+	case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		s.i = start
+		return s.errorf("invalid escape sequence")
+
+	default:
+		// Anything else is the character itself, including an escaped single quote
+		s.i--
+
+		r, size := utf8.DecodeRune(s.data[s.i:])
+		s.i += size
+
+		if r != '\u2028' && r != '\u2029' {
+			// Anything other than a line continuation
+			if size == 1 {
+				s.writeChar(c)
+			} else {
+				s.out = append(s.out, s.data[s.i-size:s.i]...)
+			}
+		}
+	}
+
+	return nil
+}
+
+// number converts a JSON5 number.
+func (s *standardizer) number() error {
+	numberStart := s.i
+	negative := false
+
+	switch s.data[s.i] {
+	case '-':
+		negative = true
+		s.i++
+
+	case '+':
+		s.i++
+	}
+
+	if s.isIdentifierStart() {
+		start := s.i
+		name := s.readIdentifier()
+
+		if name != "Infinity" && name != "NaN" {
+			s.i = start
+			return s.errorf("invalid number")
+		}
+
+		return s.nonFinite(numberStart, name)
+	}
+
+	if negative {
+		s.write("-")
+	}
+
+	if s.i+1 < len(s.data) && s.data[s.i] == '0' && (s.data[s.i+1] == 'x' || s.data[s.i+1] == 'X') {
+		s.i += 2
+		start := s.i
+
+		for s.i < len(s.data) && isHex(s.data[s.i]) {
+			s.i++
+		}
+
+		n, ok := new(big.Int).SetString(string(s.data[start:s.i]), 16)
+		if !ok {
+			return s.errorf("invalid hexadecimal number")
+		}
+
+		s.out = n.Append(s.out, 10)
+
+		return nil
+	}
+
+	intStart := s.i
+	s.skipDigits()
+
+	if s.i == intStart {
+		// A leading decimal point
+		s.write("0")
+	} else {
+		s.out = append(s.out, s.data[intStart:s.i]...)
+	}
+
+	if s.i < len(s.data) && s.data[s.i] == '.' {
+		s.i++
+
+		fracStart := s.i
+		s.skipDigits()
+
+		if s.i > fracStart {
+			s.write(".")
+			s.out = append(s.out, s.data[fracStart:s.i]...)
+		} else if s.i == intStart+1 {
+			return s.errorf("invalid number")
+		}
+	} else if s.i == intStart {
+		return s.errorf("invalid number")
+	}
+
+	if s.i < len(s.data) && (s.data[s.i] == 'e' || s.data[s.i] == 'E') {
+		start := s.i
+		s.i++
+
+		if s.i < len(s.data) && (s.data[s.i] == '+' || s.data[s.i] == '-') {
+			s.i++
+		}
+
+		s.skipDigits()
+		s.out = append(s.out, s.data[start:s.i]...)
+	}
+
+	return nil
+}
+
+// nonFinite converts Infinity or NaN, which starts at start, to null, unless it is rejected.
+func (s *standardizer) nonFinite(start int, name string) error {
+	if s.opts.RejectNonFinite {
+		s.i = start
+		return s.errorf("%s can't be represented in standard JSON", name)
+	}
+
+	s.write("null")
+
+	return nil
+}
+
+func (s *standardizer) skipDigits() {
+	for s.i < len(s.data) && '0' <= s.data[s.i] && s.data[s.i] <= '9' {
+		s.i++
+	}
+}
+
+// identifier converts an unquoted JSON5 member name, or one of true, false, null, Infinity, or NaN.
+func (s *standardizer) identifier() error {
+	start := s.i
+	name := s.readIdentifier()
+
+	// A member name is followed by a ':'
+	end := s.i
+	space := s.space
+
+	err := s.skipSpace()
+	if err != nil {
+		return err
+	}
+
+	isName := s.i < len(s.data) && s.data[s.i] == ':'
+	s.i = end
+	s.space = space
+
+	switch {
+	case isName:
+		s.out = appendQuoted(s.out, name)
+
+	case name == "true" || name == "false" || name == "null":
+		s.write(name)
+
+	case name == "Infinity" || name == "NaN":
+		return s.nonFinite(start, name)
+
+	default:
+		s.i = start
+		return s.errorf("unexpected %q", name)
+	}
+
+	return nil
+}
+
+// isIdentifierStart reports whether a JSON5 identifier starts at the current position.
+func (s *standardizer) isIdentifierStart() bool {
+	if s.i >= len(s.data) {
+		return false
+	}
+
+	r, _ := utf8.DecodeRune(s.data[s.i:])
+
+	return r == '$' || r == '_' || unicode.IsLetter(r) || unicode.Is(unicode.Nl, r)
+}
+
+// readIdentifier reads a JSON5 identifier. Unicode escapes in identifiers aren't supported.
+func (s *standardizer) readIdentifier() string {
+	start := s.i
+
+	for s.i < len(s.data) {
+		r, size := utf8.DecodeRune(s.data[s.i:])
+
+		if r != '$' && r != '_' && r != '\u200C' && r != '\u200D' && !unicode.IsLetter(r) &&
+			!unicode.Is(unicode.Nl, r) && !unicode.IsDigit(r) && !unicode.In(r, unicode.Mn, unicode.Mc, unicode.Pc) {
+			break
+		}
+
+		s.i += size
+	}
+
+	return string(s.data[start:s.i])
+}
+
+// appendQuoted appends name as a JSON string. It only has characters that can be in an identifier,
+// so nothing needs escaping.
+func appendQuoted(out []byte, name string) []byte {
+	out = append(out, '"')
+	out = append(out, name...)
+
+	return append(out, '"')
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
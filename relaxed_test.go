@@ -0,0 +1,210 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalRelaxed(t *testing.T) {
+	t.Parallel()
+
+	unmarshal := func(data string, syntax Syntax) (string, error) {
+		jn, err := Unmarshal([]byte(data), DecoderOptions{Syntax: syntax})
+		if err != nil {
+			return "", err
+		}
+
+		out, err := json.Marshal(jn)
+
+		return string(out), err
+	}
+
+	t.Run("JSONC", func(t *testing.T) {
+		t.Parallel()
+
+		for data, expected := range map[string]string{
+			`{"a": 1}`: `{"a":1}`,
+			`// A config file
+{
+	"name": "app", // the name
+	/* "disabled": true, */
+	"ports": [80, 443,],
+	"url": "http://example.com/*not a comment*/",
+	"nested": {"a": [], "b": {},},
+}
+// The end`: `{"name":"app","ports":[80,443],"url":"http://example.com/*not a comment*/","nested":{"a":[],"b":{}}}`,
+			`[1,/**/]`:   `[1]`,
+			`"a\"//b"`:   `"a\"//b"`,
+			`1/* 2 */`:   `1`,
+			"[\r\n]":     `[]`,
+			"// only\n0": `0`,
+		} {
+			for _, syntax := range []Syntax{SyntaxJSONC, SyntaxJSON5} {
+				actual, err := unmarshal(data, syntax)
+				require.NoError(t, err, "%v: %s", syntax, data)
+				require.Equal(t, expected, actual, "%v: %s", syntax, data)
+			}
+		}
+
+		for _, data := range []string{
+			`[,]`,
+			`[1,,]`,
+			`{,}`,
+			`[1 2]`,
+			`[1/**/2]`,
+			`1,`,
+			`/* unterminated`,
+			`[1] // trailing value` + "\n2",
+			`{a: 1}`,
+			`'single'`,
+			`+1`,
+			`[1 / 2]`,
+			`{"a": tr ue}`,
+		} {
+			_, err := unmarshal(data, SyntaxJSONC)
+			require.Error(t, err, data)
+		}
+
+		// Standard JSON is strict
+		_, err := unmarshal(`[1,]`, SyntaxJSON)
+		require.Error(t, err)
+
+		_, err = unmarshal(`[1] // comment`, SyntaxJSON)
+		require.Error(t, err)
+	})
+
+	t.Run("JSON5", func(t *testing.T) {
+		t.Parallel()
+
+		for data, expected := range map[string]string{
+			`{unquoted: 1, $dollar_1: 2, _: 3, ünïcode: 4, null: 5, true: 6}`: `{"unquoted":1,"$dollar_1":2,"_":3,"ünïcode":4,"null":5,"true":6}`,
+			`{a /* comment */ : 1}`:                   `{"a":1}`,
+			`['single', 'it\'s', 'say "hi"', "it's"]`: `["single","it's","say \"hi\"","it's"]`,
+			`'\x41\v\0\a\ \/B'`:                       `"A\u000b\u0000a /B"`,
+			"'line \\\ncontinued \\\r\nagain \\ '":    `"line continued again "`,
+			"'tab\there'":                             `"tab\there"`,
+			`[0x1F, -0XFF, 0x0, 0x10000000000000000]`: `[31,-255,0,18446744073709552000]`,
+			`[+1, .5, 5., -.5e1, +5.e-1, 1E3, 0.1]`:   `[1,0.5,5,-5,0.5,1000,0.1]`,
+			`[true, false, null]`:                     `[true,false,null]`,
+			"\uFEFF\u00a0\v\f[1\u2028]":               `[1]`,
+		} {
+			actual, err := unmarshal(data, SyntaxJSON5)
+			require.NoError(t, err, data)
+			require.Equal(t, expected, actual, data)
+		}
+
+		for _, data := range []string{
+			`[unquoted]`,
+			`{a b: 1}`,
+			`'unterminated`,
+			`'bad \x4'`,
+			`'bad \1'`,
+			`'bad \01'`,
+			"'line\nbreak'",
+			`[0x]`,
+			`[.]`,
+			`[+]`,
+			`[-Inf]`,
+			`[01]`,
+			`[1e]`,
+			`[Infinity:1]`,
+			`{a: 1,,}`,
+		} {
+			_, err := unmarshal(data, SyntaxJSON5)
+			require.Error(t, err, data)
+		}
+	})
+
+	t.Run("Infinity and NaN", func(t *testing.T) {
+		t.Parallel()
+
+		data := []byte(`[Infinity, -Infinity, +Infinity, NaN, -NaN, {a: NaN}]`)
+
+		jn, err := Unmarshal(data, DecoderOptions{Syntax: SyntaxJSON5})
+		require.NoError(t, err)
+
+		out, err := json.Marshal(jn)
+		require.NoError(t, err)
+		require.Equal(t, `[null,null,null,null,null,{"a":null}]`, string(out))
+
+		_, err = StandardizeWithOptions(data, SyntaxJSON5, StandardizeOptions{RejectNonFinite: true})
+		require.EqualError(t, err, "jsonnode: line 1, column 2: Infinity can't be represented in standard JSON")
+
+		_, err = Unmarshal([]byte("{\n  a: -NaN}"), DecoderOptions{Syntax: SyntaxJSON5, RejectNonFinite: true})
+		require.EqualError(t, err, "jsonnode: line 2, column 6: NaN can't be represented in standard JSON")
+	})
+
+	t.Run("error positions", func(t *testing.T) {
+		t.Parallel()
+
+		for _, test := range []struct {
+			data         string
+			syntax       Syntax
+			line, column int
+		}{
+			// Found when converting
+			{"// c\n/* unterminated", SyntaxJSONC, 2, 1},
+			{"{\n  a: 'bad \\1'}", SyntaxJSON5, 2, 11},
+
+			// Found when decoding
+			{"// c\n[1 2]", SyntaxJSONC, 2, 4},
+			{"/* one */ [1,,]", SyntaxJSONC, 1, 14},
+			{"{\n  // c\n  \"a\": tru,\n}", SyntaxJSONC, 4, 1},
+			{"{\n  a: 'x\\x41\\ty' 1,\n}", SyntaxJSON5, 2, 17},
+			{"{\n  'a': 0x10 2,\n}", SyntaxJSON5, 2, 13},
+			{"[1,\n", SyntaxJSONC, 1, 3},
+		} {
+			_, err := Unmarshal([]byte(test.data), DecoderOptions{Syntax: test.syntax})
+
+			var syntaxErr *SyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "%q: %v", test.data, err)
+			require.Equal(t, test.line, syntaxErr.Line, "%q: %v", test.data, err)
+			require.Equal(t, test.column, syntaxErr.Column, "%q: %v", test.data, err)
+		}
+
+		_, err := Unmarshal([]byte("// c\n\"abc"), DecoderOptions{Syntax: SyntaxJSONC})
+		require.True(t, errors.Is(err, io.ErrUnexpectedEOF), "%v", err)
+		require.Regexp(t, `^jsonnode: line 2, column 5: `, err.Error())
+
+		_, err = Unmarshal([]byte("// c\n[1 2]"), DecoderOptions{Syntax: SyntaxJSONC, Lazy: true})
+
+		var jsonErr *json.SyntaxError
+		require.True(t, errors.As(err, &jsonErr), "%v", err)
+		require.Regexp(t, `^jsonnode: line 2, column 4: `, err.Error())
+	})
+
+	t.Run("lazy", func(t *testing.T) {
+		t.Parallel()
+
+		jn, err := Unmarshal([]byte(`{a: [1, 2,], /* c */ b: 'x',}`), DecoderOptions{Syntax: SyntaxJSON5, Lazy: true})
+		require.NoError(t, err)
+
+		out, err := json.Marshal(jn)
+		require.NoError(t, err)
+		require.Equal(t, `{"a":[1,2],"b":"x"}`, string(out))
+		require.Equal(t, "x", jn.Get("b").Value())
+	})
+}
+
+func ExampleStandardize() {
+	config := []byte(`{
+	// Where to listen
+	port: 0x1F90,
+	hosts: ['localhost', "example.com",],
+}`)
+
+	data, err := Standardize(config, SyntaxJSON5)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(data))
+
+	// Output:
+	// {"port":8080,"hosts":["localhost","example.com"]}
+}
@@ -0,0 +1,66 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+var _ json.Marshaler = (*Synchronized)(nil)
+
+// Synchronized holds a JSON document that can be safely shared between goroutines.
+// Any number of goroutines can read it with View at the same time, while Update changes it one
+// goroutine at a time.
+type Synchronized struct {
+	mu   sync.RWMutex
+	root *JSONNode
+}
+
+// NewSynchronized creates a Synchronized holding a copy of the document (or subtree) at root.
+// If root does not exist, the document is a JSON null.
+func NewSynchronized(root *JSONNode) *Synchronized {
+	s := &Synchronized{root: root.Clone()}
+	if s.root == nil {
+		s.root = NewFromValue(nil)
+	}
+
+	return s
+}
+
+// View calls fn with the root of the document, which must only be read.
+// Any number of calls to View can run at once, but not while Update is running.
+// root and any nodes gotten from it must not be used after fn returns.
+// The error returned by fn is returned.
+func (s *Synchronized) View(fn func(root *JSONNode) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return fn(s.root)
+}
+
+// Update calls fn with the root of the document so it can be changed. Only one call to Update
+// runs at a time, and no calls to View run while it does, so readers never see a partial update.
+// fn changes the document in place, so any changes it made before returning an error are kept.
+// The error returned by fn is returned.
+// root and any nodes gotten from it must not be used after fn returns.
+func (s *Synchronized) Update(fn func(root *JSONNode) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return fn(s.root)
+}
+
+// Snapshot gets a copy of the document, which can be used without any locking.
+func (s *Synchronized) Snapshot() *JSONNode {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.root.Clone()
+}
+
+// MarshalJSON marshals the document to JSON.
+func (s *Synchronized) MarshalJSON() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.root.MarshalJSON()
+}
@@ -0,0 +1,142 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSynchronized(t *testing.T) {
+	t.Parallel()
+
+	t.Run("copy", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+		s := NewSynchronized(jn.Get("with"))
+		require.NoError(t, jn.Get("with").Delete("meat"))
+
+		require.NoError(t, s.View(func(root *JSONNode) error {
+			require.Equal(t, "prosciutto", root.Get("meat").Value())
+			return nil
+		}))
+
+		require.Equal(t, "null", marshal(t, NewSynchronized(nil)))
+	})
+
+	t.Run("update", func(t *testing.T) {
+		t.Parallel()
+
+		s := NewSynchronized(unmarshalPlatter(t))
+
+		require.NoError(t, s.Update(func(root *JSONNode) error {
+			if err := root.Set("platter", "wood"); err != nil {
+				return err
+			}
+
+			return root.Get("cheeses").Append("brie")
+		}))
+
+		snapshot := s.Snapshot()
+		require.Equal(t, "wood", snapshot.Get("platter").Value())
+		require.Equal(t, 4, snapshot.Get("cheeses").Len())
+
+		require.NoError(t, snapshot.Set("platter", "marble"))
+		require.NoError(t, s.View(func(root *JSONNode) error {
+			require.Equal(t, "wood", root.Get("platter").Value())
+			return nil
+		}))
+	})
+
+	t.Run("failed update", func(t *testing.T) {
+		t.Parallel()
+
+		s := NewSynchronized(unmarshalPlatter(t))
+
+		failed := errors.New("failed")
+		err := s.Update(func(root *JSONNode) error {
+			if err := root.Delete("cheeses"); err != nil {
+				return err
+			}
+
+			return failed
+		})
+		require.Equal(t, failed, err)
+
+		// Changes made before the error are kept
+		require.NoError(t, s.View(func(root *JSONNode) error {
+			require.Equal(t, KindMissing, root.Get("cheeses").Kind())
+			return nil
+		}))
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		s := NewSynchronized(NewFromValue(map[string]interface{}{"a": 0.0, "b": 0.0}))
+
+		const writers, readers, updates = 4, 4, 100
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for j := 0; j < updates; j++ {
+					err := s.Update(func(root *JSONNode) error {
+						// Two steps that readers must only ever see together
+						a, _ := root.Get("a").ValueAsFloat64()
+						if err := root.Set("a", a+1); err != nil {
+							return err
+						}
+
+						b, _ := root.Get("b").ValueAsFloat64()
+						return root.Set("b", b-1)
+					})
+					if err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}()
+		}
+
+		for i := 0; i < readers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for j := 0; j < updates; j++ {
+					err := s.View(func(root *JSONNode) error {
+						a, _ := root.Get("a").ValueAsFloat64()
+						b, _ := root.Get("b").ValueAsFloat64()
+						if a != -b {
+							return fmt.Errorf("saw a partial update: a=%v, b=%v", a, b)
+						}
+
+						return nil
+					})
+					if err != nil {
+						t.Error(err)
+						return
+					}
+
+					if _, err := json.Marshal(s); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		require.JSONEq(t, fmt.Sprintf(`{"a": %d, "b": -%[1]d}`, writers*updates), marshal(t, s))
+	})
+}
@@ -0,0 +1,95 @@
+package jsonnode
+
+import (
+	"sort"
+)
+
+// copyValue makes a deep copy of a value made up of JSON objects and arrays.
+// Any map[string]interface{} in value is converted into an *Object, with its members sorted by name.
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *Object:
+		obj := &Object{
+			keys:   make([]string, len(v.keys)),
+			values: make(map[string]interface{}, len(v.keys)),
+		}
+
+		copy(obj.keys, v.keys)
+
+		for key, val := range v.values {
+			obj.values[key] = copyValue(val)
+		}
+
+		return obj
+
+	case map[string]interface{}:
+		obj := NewObject()
+		for _, key := range sortedKeys(v) {
+			obj.set(key, copyValue(v[key]))
+		}
+
+		return obj
+
+	case []interface{}:
+		valSlice := make([]interface{}, len(v))
+		for i, val := range v {
+			valSlice[i] = copyValue(val)
+		}
+
+		return valSlice
+	}
+
+	// Everything else is immutable
+	return value
+}
+
+// mapValue makes a deep copy of a value made up of JSON objects and arrays, with every *Object in
+// it converted into a map[string]interface{}.
+func mapValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *Object:
+		valMap := make(map[string]interface{}, len(v.keys))
+		for key, val := range v.values {
+			valMap[key] = mapValue(val)
+		}
+
+		return valMap
+
+	case []interface{}:
+		valSlice := make([]interface{}, len(v))
+		for i, val := range v {
+			valSlice[i] = mapValue(val)
+		}
+
+		return valSlice
+	}
+
+	// Everything else is immutable
+	return value
+}
+
+// equalValues reports whether two values represent the same JSON.
+// The order of JSON object members does not matter, and numbers are compared by value.
+func equalValues(a, b interface{}) bool {
+	return newComparer(defaultEqualOptions).equal(a, b, "")
+}
+
+// equalNumbers reports whether two numbers (float64 or json.Number) have the same value.
+// 1.0 and 1, for example, are equal (see CompareNumbers).
+func equalNumbers(a, b interface{}) bool {
+	cmp, ok := CompareNumbers(a, b)
+
+	return ok && cmp == 0
+}
+
+// sortedKeys gets the keys of a map, sorted.
+func sortedKeys(valMap map[string]interface{}) []string {
+	keys := make([]string, 0, len(valMap))
+	for key := range valMap {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
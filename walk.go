@@ -0,0 +1,121 @@
+package jsonnode
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// SkipChildren can be returned by the function passed to Walk to skip the children of the node it
+// was called for. It is not returned by Walk.
+var SkipChildren = errors.New("jsonnode: skip children")
+
+// Path is the location of a node relative to another node. Each element is either a string (the
+// name of an object member) or an int (an array index), so a Path can be passed to Lookup.
+type Path []interface{}
+
+// String returns the path as a JSON Pointer (RFC 6901).
+func (p Path) String() string {
+	var sb strings.Builder
+
+	for _, segment := range p {
+		sb.WriteByte('/')
+
+		switch segment := segment.(type) {
+		case string:
+			sb.WriteString(escapePointerToken(segment))
+
+		case int:
+			sb.WriteString(strconv.Itoa(segment))
+		}
+	}
+
+	return sb.String()
+}
+
+// WalkOptions controls how Walk visits nodes.
+type WalkOptions struct {
+	// PostOrder visits the children of a node before the node itself.
+	// By default, a node is visited before its children.
+	PostOrder bool
+}
+
+// Walk calls fn for this node and each of its descendants, with each node visited before its
+// children. The members of JSON objects are visited in order.
+// path is the location of the node relative to this node, and is not shared between calls.
+// If fn returns SkipChildren, the children of that node are skipped. Any other error stops the
+// walk, and is returned.
+func (jn *JSONNode) Walk(fn func(path Path, n *JSONNode) error) error {
+	return jn.WalkWithOptions(fn, WalkOptions{})
+}
+
+// WalkWithOptions is like Walk, as controlled by opts.
+// With opts.PostOrder, returning SkipChildren has no effect, since the children have already been visited.
+func (jn *JSONNode) WalkWithOptions(fn func(path Path, n *JSONNode) error, opts WalkOptions) error {
+	if !jn.Exists() {
+		return nil
+	}
+
+	return jn.walk(Path{}, fn, opts)
+}
+
+func (jn *JSONNode) walk(path Path, fn func(path Path, n *JSONNode) error, opts WalkOptions) error {
+	if !opts.PostOrder {
+		err := fn(path, jn)
+		if errors.Is(err, SkipChildren) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// Nodes can be changed by fn, so go by whatever the value is now
+	switch value := jn.shallowValue().(type) {
+	case *Object:
+		for _, key := range value.Keys() {
+			child := jn.Get(key)
+			if child == nil {
+				// Removed by fn
+				continue
+			}
+
+			err := child.walk(appendPath(path, key), fn, opts)
+			if err != nil {
+				return err
+			}
+		}
+
+	case []interface{}:
+		for i := range value {
+			child := jn.Index(i)
+			if child == nil {
+				continue
+			}
+
+			err := child.walk(appendPath(path, i), fn, opts)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if opts.PostOrder {
+		err := fn(path, jn)
+		if err != nil && !errors.Is(err, SkipChildren) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// appendPath makes a new Path with segment added to the end of path.
+func appendPath(path Path, segment interface{}) Path {
+	newPath := make(Path, len(path)+1)
+	copy(newPath, path)
+	newPath[len(path)] = segment
+
+	return newPath
+}
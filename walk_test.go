@@ -0,0 +1,165 @@
+package jsonnode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONNodeWalk(t *testing.T) {
+	t.Parallel()
+
+	t.Run("pre-order", func(t *testing.T) {
+		t.Parallel()
+
+		var paths []string
+		err := unmarshalPlatter(t).Walk(func(path Path, n *JSONNode) error {
+			require.Equal(t, path.String(), n.JSONPointer())
+			paths = append(paths, path.String())
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, []string{
+			"",
+			"/platter",
+			"/cheeses", "/cheeses/0", "/cheeses/1", "/cheeses/2",
+			"/with",
+			"/with/fruit",
+			"/with/fruit/0", "/with/fruit/0/type", "/with/fruit/0/count",
+			"/with/fruit/1", "/with/fruit/1/type", "/with/fruit/1/count",
+			"/with/meat",
+		}, paths)
+	})
+
+	t.Run("post-order", func(t *testing.T) {
+		t.Parallel()
+
+		var paths []string
+		err := unmarshalPlatter(t).Get("with").WalkWithOptions(func(path Path, n *JSONNode) error {
+			paths = append(paths, path.String())
+			return nil
+		}, WalkOptions{PostOrder: true})
+		require.NoError(t, err)
+
+		require.Equal(t, []string{
+			"/fruit/0/type", "/fruit/0/count", "/fruit/0",
+			"/fruit/1/type", "/fruit/1/count", "/fruit/1",
+			"/fruit",
+			"/meat",
+			"",
+		}, paths)
+	})
+
+	t.Run("skip children", func(t *testing.T) {
+		t.Parallel()
+
+		var paths []Path
+		err := unmarshalPlatter(t).Walk(func(path Path, n *JSONNode) error {
+			paths = append(paths, path)
+
+			if n.IsArray() {
+				return SkipChildren
+			}
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, []Path{{}, {"platter"}, {"cheeses"}, {"with"}, {"with", "fruit"}, {"with", "meat"}}, paths)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		t.Parallel()
+
+		stop := errors.New("stop")
+		count := 0
+
+		err := unmarshalPlatter(t).Walk(func(path Path, n *JSONNode) error {
+			count++
+			if len(path) == 2 {
+				return stop
+			}
+
+			return nil
+		})
+		require.Equal(t, stop, err)
+		require.Equal(t, 4, count)
+	})
+
+	t.Run("modify", func(t *testing.T) {
+		t.Parallel()
+
+		jn := unmarshalPlatter(t)
+		err := jn.Walk(func(path Path, n *JSONNode) error {
+			if s, ok := n.ValueAsString(); ok {
+				return n.SetPointer("", strings.ToUpper(s))
+			}
+
+			if n.Get("count") != nil {
+				return n.Delete("count")
+			}
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.JSONEq(t, `{
+			"platter": "SLATE",
+			"cheeses": ["CHEDDAR", "SWISS", "MANCHEGO"],
+			"with": {"fruit": [{"type": "GRAPES"}, {"type": "STRAWBERRIES"}], "meat": "PROSCIUTTO"}
+		}`, marshal(t, jn))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+
+		var jn *JSONNode
+		require.NoError(t, jn.Walk(func(Path, *JSONNode) error {
+			t.Fatal("should not be called")
+			return nil
+		}))
+	})
+
+	t.Run("path", func(t *testing.T) {
+		t.Parallel()
+
+		path := Path{"a/b", 0, "c~d"}
+		require.Equal(t, "/a~1b/0/c~0d", path.String())
+
+		jn := NewFromValue(map[string]interface{}{"a/b": []interface{}{map[string]interface{}{"c~d": true}}})
+		b, err := jn.Bool(path...)
+		require.NoError(t, err)
+		require.True(t, b)
+	})
+}
+
+func ExampleJSONNode_Walk() {
+	jn := new(JSONNode)
+	err := json.Unmarshal([]byte(`{"id": 1, "items": [{"id": 2}, {"id": 3, "parts": [{"id": 4}]}]}`), jn)
+	if err != nil {
+		panic(err)
+	}
+
+	err = jn.Walk(func(path Path, n *JSONNode) error {
+		if len(path) > 0 && path[len(path)-1] == "id" {
+			fmt.Println(path, n.Value())
+		}
+
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// Output:
+	// /id 1
+	// /items/0/id 2
+	// /items/1/id 3
+	// /items/1/parts/0/id 4
+}